@@ -0,0 +1,19 @@
+package config
+
+// deprecatedStorageConfig 是旧版单存储配置, 仅保留用于兼容 [storage] 配置块, 未来会被移除
+type deprecatedStorageConfig struct {
+	Type     string `toml:"type" mapstructure:"type"`
+	BasePath string `toml:"base_path" mapstructure:"base_path"`
+}
+
+// transformDeprecatedStorageConfig 将旧版 [storage] 配置转换为新版 Storages 中的一项
+func transformDeprecatedStorageConfig() {
+	Cfg.Storages = append(Cfg.Storages, StorageConfig{
+		Name:   "default",
+		Type:   Cfg.DeprecatedStorage.Type,
+		Enable: true,
+		Options: map[string]any{
+			"base_path": Cfg.DeprecatedStorage.BasePath,
+		},
+	})
+}
@@ -0,0 +1,10 @@
+package config
+
+// MatchConfig 描述附加在某个存储上的自动路由匹配条件, 所有字段均为可选, 留空表示不限制该维度
+type MatchConfig struct {
+	Extensions []string `toml:"extensions" mapstructure:"extensions" json:"extensions,omitempty"`
+	MimeTypes  []string `toml:"mime_types" mapstructure:"mime_types" json:"mime_types,omitempty"`
+	MinSize    int64    `toml:"min_size" mapstructure:"min_size" json:"min_size,omitempty"`
+	MaxSize    int64    `toml:"max_size" mapstructure:"max_size" json:"max_size,omitempty"`
+	ChatIDs    []int64  `toml:"chat_ids" mapstructure:"chat_ids" json:"chat_ids,omitempty"`
+}
@@ -0,0 +1,29 @@
+package config
+
+import "github.com/spf13/viper"
+
+// NotifyConfig 描述一个通知渠道, Options 承载各渠道类型私有的字段(如 pushplus 的 token/topic)
+type NotifyConfig struct {
+	Name   string `toml:"name" mapstructure:"name" json:"name"`
+	Type   string `toml:"type" mapstructure:"type" json:"type"`
+	Enable bool   `toml:"enable" mapstructure:"enable" json:"enable"`
+
+	Options map[string]any `toml:",remain" mapstructure:",remain" json:"options,omitempty"`
+}
+
+func (n NotifyConfig) GetName() string {
+	return n.Name
+}
+
+func (n NotifyConfig) GetType() string {
+	return n.Type
+}
+
+// LoadNotifyConfigs 从 `notify` 配置项解析出所有通知渠道配置
+func LoadNotifyConfigs(v *viper.Viper) ([]NotifyConfig, error) {
+	var notifiers []NotifyConfig
+	if err := v.UnmarshalKey("notify", &notifiers); err != nil {
+		return nil, err
+	}
+	return notifiers, nil
+}
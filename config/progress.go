@@ -0,0 +1,16 @@
+package config
+
+// progressConfig 控制下载进度上报的平滑速度计算与 Telegram 编辑消息的限流
+type progressConfig struct {
+	MinEditIntervalMS int64   `toml:"min_edit_interval_ms" mapstructure:"min_edit_interval_ms" json:"min_edit_interval_ms"`
+	MinPercentDelta   float64 `toml:"min_percent_delta" mapstructure:"min_percent_delta" json:"min_percent_delta"`
+	BarWidth          int     `toml:"bar_width" mapstructure:"bar_width" json:"bar_width"`
+	EWMAAlpha         float64 `toml:"ewma_alpha" mapstructure:"ewma_alpha" json:"ewma_alpha"`
+}
+
+// GetProgressConfig 线程安全地读取当前的进度上报配置
+func GetProgressConfig() progressConfig {
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
+	return Cfg.Progress
+}
@@ -0,0 +1,31 @@
+package config
+
+import "github.com/spf13/viper"
+
+// StorageConfig 是所有存储后端的公共配置字段, Options 承载各存储类型私有的字段(如 webdav 的 url/username/password)
+type StorageConfig struct {
+	Name   string `toml:"name" mapstructure:"name" json:"name"`
+	Type   string `toml:"type" mapstructure:"type" json:"type"`
+	Enable bool   `toml:"enable" mapstructure:"enable" json:"enable"`
+
+	Match *MatchConfig `toml:"match" mapstructure:"match" json:"match,omitempty"`
+
+	Options map[string]any `toml:",remain" mapstructure:",remain" json:"options,omitempty"`
+}
+
+func (s StorageConfig) GetName() string {
+	return s.Name
+}
+
+func (s StorageConfig) GetType() string {
+	return s.Type
+}
+
+// LoadStorageConfigs 从 `storages` 配置项解析出所有存储配置
+func LoadStorageConfigs(v *viper.Viper) ([]StorageConfig, error) {
+	var storages []StorageConfig
+	if err := v.UnmarshalKey("storages", &storages); err != nil {
+		return nil, err
+	}
+	return storages, nil
+}
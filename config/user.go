@@ -0,0 +1,39 @@
+package config
+
+// userConfig 描述单个用户的访问控制配置
+type userConfig struct {
+	ID        int64    `toml:"id" mapstructure:"id" json:"id"`
+	Storages  []string `toml:"storages" mapstructure:"storages" json:"storages"`
+	Blacklist bool     `toml:"blacklist" mapstructure:"blacklist" json:"blacklist"`
+
+	// UseRouter 为 true 时, 保存任务的目标存储由 storage/router 根据 match 规则自动选择,
+	// 而不需要用户在 Telegram 交互中手动挑选
+	UseRouter bool `toml:"use_router" mapstructure:"use_router" json:"use_router"`
+
+	// Notify 列出该用户订阅的通知渠道名称, 对应 notify 配置段中的 name
+	Notify []string `toml:"notify" mapstructure:"notify" json:"notify"`
+}
+
+// ShouldUseRouter 返回指定用户是否开启了默认路由模式
+func ShouldUseRouter(userID int64) bool {
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
+	for _, u := range Cfg.Users {
+		if u.ID == userID {
+			return u.UseRouter
+		}
+	}
+	return false
+}
+
+// UserNotifiers 返回指定用户订阅的通知渠道名称
+func UserNotifiers(userID int64) []string {
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
+	for _, u := range Cfg.Users {
+		if u.ID == userID {
+			return u.Notify
+		}
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"sync"
 
 	"github.com/spf13/viper"
 )
@@ -11,14 +12,17 @@ import (
 type Config struct {
 	Workers      int          `toml:"workers" mapstructure:"workers"`
 	Retry        int          `toml:"retry" mapstructure:"retry"`
+	Threads      int          `toml:"threads" mapstructure:"threads" json:"threads"`
 	NoCleanCache bool         `toml:"no_clean_cache" mapstructure:"no_clean_cache" json:"no_clean_cache"`
 	Users        []userConfig `toml:"users" mapstructure:"users" json:"users"`
 
 	Temp     tempConfig      `toml:"temp" mapstructure:"temp"`
+	Progress progressConfig  `toml:"progress" mapstructure:"progress"`
 	Log      logConfig       `toml:"log" mapstructure:"log"`
 	DB       dbConfig        `toml:"db" mapstructure:"db"`
 	Telegram telegramConfig  `toml:"telegram" mapstructure:"telegram"`
 	Storages []StorageConfig `toml:"-" mapstructure:"-" json:"storages"`
+	Notify   []NotifyConfig  `toml:"-" mapstructure:"-" json:"notify"`
 	// Deprecated
 	DeprecatedStorage deprecatedStorageConfig `toml:"storage" mapstructure:"storage"`
 }
@@ -55,6 +59,37 @@ type proxyConfig struct {
 
 var Cfg *Config
 
+// cfgMu 保护 Cfg 的并发读写, 使得 watchConfig 触发的热重载对运行中的 goroutine 是安全的
+var cfgMu sync.RWMutex
+
+// GetRetry 线程安全地读取当前的重试次数配置
+func GetRetry() int {
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
+	return Cfg.Retry
+}
+
+// GetCacheTTL 线程安全地读取当前的缓存过期时间配置
+func GetCacheTTL() int64 {
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
+	return Cfg.Temp.CacheTTL
+}
+
+// GetThreads 线程安全地读取当前的并发下载线程数配置
+func GetThreads() int {
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
+	return Cfg.Threads
+}
+
+// GetStorages 线程安全地读取当前的存储配置列表
+func GetStorages() []StorageConfig {
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
+	return Cfg.Storages
+}
+
 func Init() error {
 	viper.SetConfigName("config")
 	viper.AddConfigPath(".")
@@ -67,6 +102,7 @@ func Init() error {
 
 	viper.SetDefault("workers", 3)
 	viper.SetDefault("retry", 3)
+	viper.SetDefault("threads", 4)
 
 	viper.SetDefault("telegram.app_id", 1025907)
 	viper.SetDefault("telegram.app_hash", "452b0359b988148995f22ff0f4229750")
@@ -74,6 +110,11 @@ func Init() error {
 	viper.SetDefault("temp.base_path", "cache/")
 	viper.SetDefault("temp.cache_ttl", 3600)
 
+	viper.SetDefault("progress.min_edit_interval_ms", 3000)
+	viper.SetDefault("progress.min_percent_delta", 1.0)
+	viper.SetDefault("progress.bar_width", 20)
+	viper.SetDefault("progress.ewma_alpha", 0.3)
+
 	viper.SetDefault("log.level", "INFO")
 	viper.SetDefault("log.file", "logs/saveany.log")
 	viper.SetDefault("log.backup_count", 7)
@@ -115,6 +156,12 @@ func Init() error {
 	}
 	Cfg.Storages = storagesConfig
 
+	notifyConfigs, err := LoadNotifyConfigs(viper.GetViper())
+	if err != nil {
+		return fmt.Errorf("error loading notify configs: %w", err)
+	}
+	Cfg.Notify = notifyConfigs
+
 	if Cfg.DeprecatedStorage != (deprecatedStorageConfig{}) {
 		fmt.Println("\n警告: 你正在使用旧版存储配置, 未来版本将会被废弃.\n请参考新的配置文件模板.")
 		transformDeprecatedStorageConfig()
@@ -137,6 +184,8 @@ func Init() error {
 		return fmt.Errorf("workers 和 retry 必须大于 0, 当前值: workers=%d, retry=%d", Cfg.Workers, Cfg.Retry)
 	}
 
+	watchConfig()
+
 	return nil
 }
 
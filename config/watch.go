@@ -0,0 +1,145 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+
+	"github.com/krau/SaveAny-Bot/logger"
+)
+
+// reloadDebounce 合并编辑器保存时产生的连续写入/重命名事件
+const reloadDebounce = 500 * time.Millisecond
+
+// reloadHooks 在新配置通过校验并替换 Cfg 之后依次调用, 用于让 storage 等持有独立注册表的包
+// 在不引入对 config 包反向依赖的前提下, 把自己的注册表与新配置对账; 通过 OnReload 注册
+var reloadHooks []func(oldCfg, newCfg *Config)
+
+// OnReload 注册一个热重载回调, 在 reloadConfig 成功替换 Cfg 后按注册顺序调用
+func OnReload(fn func(oldCfg, newCfg *Config)) {
+	reloadHooks = append(reloadHooks, fn)
+}
+
+// watchConfig 监听 config.toml 的变更, 解析并校验通过后才原子替换 Cfg
+func watchConfig() {
+	var debounceTimer *time.Timer
+	viper.OnConfigChange(func(e fsnotify.Event) {
+		if debounceTimer != nil {
+			debounceTimer.Stop()
+		}
+		debounceTimer = time.AfterFunc(reloadDebounce, func() {
+			if err := reloadConfig(); err != nil {
+				logger.L.Errorf("热重载配置失败, 已保留旧配置: %s", err)
+			}
+		})
+	})
+	viper.WatchConfig()
+}
+
+// reloadConfig 解析并校验新配置, 校验通过后才替换 Cfg, 否则保留旧配置
+func reloadConfig() error {
+	newCfg := &Config{}
+	if err := viper.Unmarshal(newCfg); err != nil {
+		return fmt.Errorf("error unmarshalling config file: %w", err)
+	}
+
+	storagesConfig, err := LoadStorageConfigs(viper.GetViper())
+	if err != nil {
+		return fmt.Errorf("error loading storage configs: %w", err)
+	}
+	newCfg.Storages = storagesConfig
+
+	notifyConfigs, err := LoadNotifyConfigs(viper.GetViper())
+	if err != nil {
+		return fmt.Errorf("error loading notify configs: %w", err)
+	}
+	newCfg.Notify = notifyConfigs
+
+	if err := validateConfig(newCfg); err != nil {
+		return err
+	}
+
+	cfgMu.Lock()
+	oldCfg := Cfg
+	Cfg = newCfg
+	cfgMu.Unlock()
+
+	logConfigDiff(oldCfg, newCfg)
+
+	for _, hook := range reloadHooks {
+		hook(oldCfg, newCfg)
+	}
+	return nil
+}
+
+// validateConfig 校验热重载后的配置是否合法, 规则与 Init 中的校验保持一致
+func validateConfig(cfg *Config) error {
+	if cfg.Workers < 1 || cfg.Retry < 1 {
+		return fmt.Errorf("workers 和 retry 必须大于 0, 当前值: workers=%d, retry=%d", cfg.Workers, cfg.Retry)
+	}
+
+	storageNames := make(map[string]struct{})
+	for _, storage := range cfg.Storages {
+		if _, ok := storageNames[storage.GetName()]; ok {
+			return fmt.Errorf("重复的存储名: %s", storage.GetName())
+		}
+		storageNames[storage.GetName()] = struct{}{}
+	}
+
+	return nil
+}
+
+// logConfigDiff 汇总新增/移除的存储以及 ACL 变更的用户, 输出一条摘要日志
+func logConfigDiff(oldCfg, newCfg *Config) {
+	oldStorages := make(map[string]struct{}, len(oldCfg.Storages))
+	for _, s := range oldCfg.Storages {
+		oldStorages[s.GetName()] = struct{}{}
+	}
+	newStorages := make(map[string]struct{}, len(newCfg.Storages))
+	for _, s := range newCfg.Storages {
+		newStorages[s.GetName()] = struct{}{}
+	}
+
+	var added, removed []string
+	for name := range newStorages {
+		if _, ok := oldStorages[name]; !ok {
+			added = append(added, name)
+		}
+	}
+	for name := range oldStorages {
+		if _, ok := newStorages[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+
+	oldUsers := make(map[int64]userConfig, len(oldCfg.Users))
+	for _, u := range oldCfg.Users {
+		oldUsers[u.ID] = u
+	}
+	var changedACL []int64
+	for _, u := range newCfg.Users {
+		if old, ok := oldUsers[u.ID]; ok && !sameUserACL(old, u) {
+			changedACL = append(changedACL, u.ID)
+		}
+	}
+
+	if len(added) == 0 && len(removed) == 0 && len(changedACL) == 0 {
+		logger.L.Infof("配置已热重载, workers=%d, retry=%d", newCfg.Workers, newCfg.Retry)
+		return
+	}
+	logger.L.Infof("配置已热重载: 新增存储 %v, 移除存储 %v, ACL 变更用户 %v", added, removed, changedACL)
+}
+
+func sameUserACL(a, b userConfig) bool {
+	if a.Blacklist != b.Blacklist || len(a.Storages) != len(b.Storages) {
+		return false
+	}
+	for i := range a.Storages {
+		if a.Storages[i] != b.Storages[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,56 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/celestix/gotgproto/ext"
+	"github.com/gotd/td/tg"
+)
+
+// DryRunRouteCommand 是 `/dryrun <文件名> <大小(字节)> [mime类型] [chatID]` 管理员命令的处理函数,
+// 预览一组样例参数会被路由到哪个存储, 而不需要真的发起下载; 命令应只注册给管理员用户
+func DryRunRouteCommand(ctx *ext.Context, u *ext.Update) error {
+	filename, size, mimeType, chatID, err := parseDryRunArgs(strings.Fields(u.EffectiveMessage.Text))
+	if err != nil {
+		return replyText(ctx, u, err.Error())
+	}
+
+	name, matched := DryRunRoute(filename, size, mimeType, chatID)
+	if name == "" {
+		return replyText(ctx, u, "未匹配到任何存储, 且未配置兜底存储")
+	}
+	return replyText(ctx, u, fmt.Sprintf("匹配到存储: %s (命中规则: %v)", name, matched))
+}
+
+// parseDryRunArgs 解析 `/dryrun <文件名> <大小(字节)> [mime类型] [chatID]` 的参数, args[0] 为命令本身
+func parseDryRunArgs(args []string) (filename string, size int64, mimeType string, chatID int64, err error) {
+	if len(args) < 3 {
+		return "", 0, "", 0, fmt.Errorf("用法: /dryrun <文件名> <大小(字节)> [mime类型] [chatID]")
+	}
+
+	filename = args[1]
+	size, err = strconv.ParseInt(args[2], 10, 64)
+	if err != nil {
+		return "", 0, "", 0, fmt.Errorf("大小参数不合法: %s", args[2])
+	}
+
+	if len(args) > 3 {
+		mimeType = args[3]
+	}
+	if len(args) > 4 {
+		chatID, err = strconv.ParseInt(args[4], 10, 64)
+		if err != nil {
+			return "", 0, "", 0, fmt.Errorf("chatID 参数不合法: %s", args[4])
+		}
+	}
+	return filename, size, mimeType, chatID, nil
+}
+
+func replyText(ctx *ext.Context, u *ext.Update, text string) error {
+	_, err := ctx.SendMessage(u.EffectiveChat().GetID(), &tg.MessagesSendMessageRequest{
+		Message: text,
+	})
+	return err
+}
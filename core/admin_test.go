@@ -0,0 +1,41 @@
+package core
+
+import "testing"
+
+func TestParseDryRunArgsMinimal(t *testing.T) {
+	filename, size, mimeType, chatID, err := parseDryRunArgs([]string{"/dryrun", "movie.mkv", "314572800"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if filename != "movie.mkv" || size != 314572800 || mimeType != "" || chatID != 0 {
+		t.Fatalf("unexpected parse result: %q %d %q %d", filename, size, mimeType, chatID)
+	}
+}
+
+func TestParseDryRunArgsWithMimeAndChatID(t *testing.T) {
+	filename, size, mimeType, chatID, err := parseDryRunArgs([]string{"/dryrun", "photo.jpg", "1024", "image/jpeg", "123"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if filename != "photo.jpg" || size != 1024 || mimeType != "image/jpeg" || chatID != 123 {
+		t.Fatalf("unexpected parse result: %q %d %q %d", filename, size, mimeType, chatID)
+	}
+}
+
+func TestParseDryRunArgsRejectsTooFewArgs(t *testing.T) {
+	if _, _, _, _, err := parseDryRunArgs([]string{"/dryrun", "photo.jpg"}); err == nil {
+		t.Fatal("expected error when size argument is missing")
+	}
+}
+
+func TestParseDryRunArgsRejectsNonNumericSize(t *testing.T) {
+	if _, _, _, _, err := parseDryRunArgs([]string{"/dryrun", "photo.jpg", "not-a-number"}); err == nil {
+		t.Fatal("expected error for non-numeric size")
+	}
+}
+
+func TestParseDryRunArgsRejectsNonNumericChatID(t *testing.T) {
+	if _, _, _, _, err := parseDryRunArgs([]string{"/dryrun", "photo.jpg", "1024", "image/jpeg", "not-a-number"}); err == nil {
+		t.Fatal("expected error for non-numeric chatID")
+	}
+}
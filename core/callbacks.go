@@ -0,0 +1,105 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/celestix/gotgproto/ext"
+	"github.com/gotd/td/tg"
+	"github.com/krau/SaveAny-Bot/db"
+	"github.com/krau/SaveAny-Bot/logger"
+	"github.com/krau/SaveAny-Bot/storage"
+)
+
+const (
+	resumeCallbackPrefix  = "resume "
+	discardCallbackPrefix = "discard_resume "
+)
+
+// HandleResumeCallback 响应 ResumeIncompleteTasks 发出的「继续上传」按钮: 按 callback data 中的 task.Key()
+// 取出持久化的上传状态, 找回对应的 ResumableStorage 后从上次提交的 offset 继续上传, 不重新开始整个文件
+func HandleResumeCallback(ctx *ext.Context, u *ext.Update) error {
+	taskKey := strings.TrimPrefix(string(u.CallbackQuery.Data), resumeCallbackPrefix)
+
+	state, err := db.GetUploadState(taskKey)
+	if err != nil || state == nil {
+		return editCallbackMessage(ctx, u, "未找到对应的上传状态, 可能已被清理")
+	}
+
+	s, err := storage.Get(state.StorageName)
+	if err != nil {
+		return editCallbackMessage(ctx, u, fmt.Sprintf("存储 %s 不存在, 无法续传", state.StorageName))
+	}
+	resumableStorage, ok := s.(storage.ResumableStorage)
+	if !ok {
+		return editCallbackMessage(ctx, u, fmt.Sprintf("存储 %s 不支持断点续传", state.StorageName))
+	}
+
+	if err := resumeUploadState(context.Background(), resumableStorage, state); err != nil {
+		logger.L.Errorf("续传任务 %s 失败: %s", taskKey, err)
+		return editCallbackMessage(ctx, u, fmt.Sprintf("续传失败: %s", err))
+	}
+	return editCallbackMessage(ctx, u, "续传完成")
+}
+
+// HandleDiscardCallback 响应「放弃」按钮: 删除持久化的上传状态和本地缓存文件, 不再询问是否续传
+func HandleDiscardCallback(ctx *ext.Context, u *ext.Update) error {
+	taskKey := strings.TrimPrefix(string(u.CallbackQuery.Data), discardCallbackPrefix)
+
+	if state, err := db.GetUploadState(taskKey); err == nil && state != nil && state.LocalFilePath != "" {
+		if rmErr := os.Remove(state.LocalFilePath); rmErr != nil && !os.IsNotExist(rmErr) {
+			logger.L.Errorf("清理任务 %s 的本地缓存文件失败: %s", taskKey, rmErr)
+		}
+	}
+	if err := db.DeleteUploadState(taskKey); err != nil {
+		logger.L.Errorf("删除任务 %s 的上传状态失败: %s", taskKey, err)
+	}
+	return editCallbackMessage(ctx, u, "已放弃该未完成的上传")
+}
+
+// resumeUploadState 从 state 持久化的 offset(经远端确认的 offset 覆盖)开始继续上传本地缓存文件剩余的字节,
+// 成功后校验 checksum 并清理上传状态和缓存文件
+func resumeUploadState(ctx context.Context, resumableStorage storage.ResumableStorage, state *storage.UploadState) error {
+	localFile, err := OpenTaskLocalFile(state.LocalFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to open cached local file: %w", err)
+	}
+	defer localFile.Close()
+
+	if offset, offsetErr := resumableStorage.GetOffset(ctx, state.UploadID); offsetErr == nil {
+		state.Offset = offset
+	}
+
+	if err := storage.UploadChunks(ctx, resumableStorage, state.UploadID, localFile, localFile.size, state.Offset, state.ChunkSize, func(offset int64) {
+		state.Offset = offset
+		if err := db.SaveUploadState(state); err != nil {
+			logger.L.Errorf("Failed to persist upload state: %s", err)
+		}
+	}); err != nil {
+		return err
+	}
+
+	checksum, err := fileChecksum(state.LocalFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to checksum local file: %w", err)
+	}
+	if err := resumableStorage.FinishUpload(ctx, state.UploadID, checksum); err != nil {
+		return err
+	}
+
+	if err := db.DeleteUploadState(state.TaskKey); err != nil {
+		logger.L.Errorf("Failed to clean up upload state: %s", err)
+	}
+	cleanCacheFile(state.LocalFilePath)
+	return nil
+}
+
+func editCallbackMessage(ctx *ext.Context, u *ext.Update, text string) error {
+	_, err := ctx.EditMessage(u.EffectiveChat().GetID(), &tg.MessagesEditMessageRequest{
+		ID:      u.EffectiveMessage.ID,
+		Message: text,
+	})
+	return err
+}
@@ -0,0 +1,165 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+	"github.com/krau/SaveAny-Bot/bot"
+	"github.com/krau/SaveAny-Bot/config"
+	"github.com/krau/SaveAny-Bot/logger"
+	"github.com/krau/SaveAny-Bot/types"
+)
+
+// downloadChunkSize 与 processPhoto 中单块下载保持一致, 对齐 Telegram 要求的分块大小
+const downloadChunkSize = 1024 * 1024
+
+// fileGetter 只声明分块下载依赖的那一个 gotd RPC 方法, 便于测试用桩实现替换真实的 bot.Client.API()
+type fileGetter interface {
+	UploadGetFile(ctx context.Context, request *tg.UploadGetFileRequest) (tg.UploadFileClass, error)
+}
+
+type chunkJob struct {
+	offset int64
+	limit  int64
+}
+
+// chunkJobs 把 [0, size) 按 chunkSize 切成一系列不重叠/无缝隙的 offset 区间
+func chunkJobs(size, chunkSize int64) []chunkJob {
+	if size <= 0 {
+		return nil
+	}
+	jobs := make([]chunkJob, 0, size/chunkSize+1)
+	for offset := int64(0); offset < size; offset += chunkSize {
+		limit := chunkSize
+		if remaining := size - offset; remaining < limit {
+			limit = remaining
+		}
+		jobs = append(jobs, chunkJob{offset: offset, limit: limit})
+	}
+	return jobs
+}
+
+// downloadChunked 用 threads 个并发 worker 把 task 对应的 Telegram 文件下载进 localFile,
+// threads < 2 时交给 downloadFile 回退到单线程顺序下载; 是否按文件大小回退由调用方传入的 threads 决定
+// (getTaskThreads 对 50MB 及以下的文件返回 1)
+func downloadChunked(ctx context.Context, task *types.Task, localFile *TaskLocalFile, threads int) error {
+	return downloadFile(ctx, bot.Client.API(), task.File.Location, task.File.FileSize, localFile, threads)
+}
+
+// downloadFile 是 downloadChunked 去掉 *types.Task 依赖后的纯逻辑实现, 分离出来便于用桩 fileGetter 做单元测试/基准测试
+func downloadFile(ctx context.Context, api fileGetter, location tg.InputFileLocationClass, size int64, localFile *TaskLocalFile, threads int) error {
+	if threads < 2 {
+		return downloadSequential(ctx, api, location, size, localFile)
+	}
+
+	downloadCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan chunkJob)
+	go func() {
+		defer close(jobs)
+		for _, j := range chunkJobs(size, downloadChunkSize) {
+			select {
+			case jobs <- j:
+			case <-downloadCtx.Done():
+				return
+			}
+		}
+	}()
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	for i := 0; i < threads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				if err := downloadChunkWithRetry(downloadCtx, api, location, localFile, j.offset, j.limit); err != nil {
+					errOnce.Do(func() { firstErr = err })
+					cancel()
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// downloadSequential 单线程按 downloadChunkSize 顺序下载整个文件, 作为 downloadFile 在小文件/单线程场景下的回退路径
+func downloadSequential(ctx context.Context, api fileGetter, location tg.InputFileLocationClass, size int64, localFile *TaskLocalFile) error {
+	for _, j := range chunkJobs(size, downloadChunkSize) {
+		if err := downloadChunkWithRetry(ctx, api, location, localFile, j.offset, j.limit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// downloadChunkWithRetry 下载 [offset, offset+limit) 这一块, 失败时按配置的重试次数重试而不影响其他块的下载,
+// 命中 FLOOD_WAIT 时按 MTProto 层返回的等待时间退避
+func downloadChunkWithRetry(ctx context.Context, api fileGetter, location tg.InputFileLocationClass, localFile *TaskLocalFile, offset, limit int64) error {
+	retry := config.GetRetry()
+	var lastErr error
+	for i := 0; i <= retry; i++ {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		data, err := fetchChunk(ctx, api, location, offset, limit)
+		if err != nil {
+			lastErr = err
+			if wait, ok := floodWaitDuration(err); ok {
+				logger.L.Warnf("Hit FLOOD_WAIT on chunk at offset %d, waiting %s", offset, wait)
+				select {
+				case <-time.After(wait):
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+				continue
+			}
+			logger.L.Errorf("Failed to download chunk at offset %d: %s, retrying...", offset, err)
+			continue
+		}
+
+		if _, err := localFile.WriteAt(data, offset); err != nil {
+			return fmt.Errorf("failed to write chunk at offset %d: %w", offset, err)
+		}
+		return nil
+	}
+	return fmt.Errorf("failed to download chunk at offset %d after %d retries: %w", offset, retry, lastErr)
+}
+
+func fetchChunk(ctx context.Context, api fileGetter, location tg.InputFileLocationClass, offset, limit int64) ([]byte, error) {
+	res, err := api.UploadGetFile(ctx, &tg.UploadGetFileRequest{
+		Location: location,
+		Offset:   offset,
+		Limit:    int(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file chunk: %w", err)
+	}
+
+	result, ok := res.(*tg.UploadFile)
+	if !ok {
+		return nil, fmt.Errorf("unexpected type %T", res)
+	}
+	return result.Bytes, nil
+}
+
+// floodWaitDuration 判断 err 是否是 MTProto 层返回的 FLOOD_WAIT 错误, 是的话返回需要等待的时长
+func floodWaitDuration(err error) (time.Duration, bool) {
+	var rpcErr *tgerr.Error
+	if errors.As(err, &rpcErr) && rpcErr.IsOneOf("FLOOD_WAIT") {
+		return time.Duration(rpcErr.Argument) * time.Second, true
+	}
+	return 0, false
+}
@@ -0,0 +1,209 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gotd/td/tg"
+	"github.com/krau/SaveAny-Bot/config"
+)
+
+// TestMain seeds config.Cfg so downloadChunkWithRetry's call to config.GetRetry() has a non-nil config to read,
+// mirroring the defaults config.Init() would otherwise set up from config.toml
+func TestMain(m *testing.M) {
+	config.Cfg = &config.Config{Retry: 3, Threads: 4}
+	os.Exit(m.Run())
+}
+
+// stubFileGetter 模拟 UploadGetFile RPC: 按 offset 从内存中的 content 切片返回数据,
+// failOffsets 中登记的 offset 第一次请求时返回错误, 之后(重试时)才成功
+type stubFileGetter struct {
+	content []byte
+	latency time.Duration
+
+	mu          sync.Mutex
+	failOffsets map[int64]bool
+	calls       []int64
+}
+
+func (s *stubFileGetter) UploadGetFile(ctx context.Context, req *tg.UploadGetFileRequest) (tg.UploadFileClass, error) {
+	if s.latency > 0 {
+		time.Sleep(s.latency)
+	}
+
+	offset := req.Offset
+	s.mu.Lock()
+	s.calls = append(s.calls, offset)
+	shouldFail := s.failOffsets[offset]
+	if shouldFail {
+		delete(s.failOffsets, offset)
+	}
+	s.mu.Unlock()
+
+	if shouldFail {
+		return nil, errors.New("injected transient error")
+	}
+
+	end := offset + int64(req.Limit)
+	if end > int64(len(s.content)) {
+		end = int64(len(s.content))
+	}
+	return &tg.UploadFile{Bytes: append([]byte(nil), s.content[offset:end]...)}, nil
+}
+
+func newTestLocalFile(t *testing.T, size int64) *TaskLocalFile {
+	t.Helper()
+	return newTestLocalFileWithCallback(t, size, nil)
+}
+
+func newTestLocalFileWithCallback(t *testing.T, size int64, progressCallback func(bytesRead, contentLength int64, speedBPS float64)) *TaskLocalFile {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "download.bin")
+	f, err := NewTaskLocalFile(path, size, progressCallback)
+	if err != nil {
+		t.Fatalf("failed to create local file: %s", err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return f
+}
+
+func readAll(t *testing.T, f *TaskLocalFile) []byte {
+	t.Helper()
+	data, err := os.ReadFile(f.file.Name())
+	if err != nil {
+		t.Fatalf("failed to read local file: %s", err)
+	}
+	return data
+}
+
+func TestDownloadFileChunkedCoversWholeFileNoGapsOrOverlaps(t *testing.T) {
+	size := int64(downloadChunkSize*3 + 1024)
+	content := make([]byte, size)
+	for i := range content {
+		content[i] = byte(i % 251)
+	}
+
+	stub := &stubFileGetter{content: content, failOffsets: map[int64]bool{downloadChunkSize: true}}
+	localFile := newTestLocalFile(t, size)
+
+	if err := downloadFile(context.Background(), stub, nil, size, localFile, 4); err != nil {
+		t.Fatalf("downloadFile failed: %s", err)
+	}
+
+	got := readAll(t, localFile)
+	if !bytes.Equal(got, content) {
+		t.Fatal("downloaded content does not match source, indicating gaps/overlaps")
+	}
+
+	wantChunks := len(chunkJobs(size, downloadChunkSize))
+	if len(stub.calls) != wantChunks+1 { // +1 for the injected retry
+		t.Fatalf("expected %d calls (including one retry), got %d", wantChunks+1, len(stub.calls))
+	}
+}
+
+// TestTaskLocalFileConcurrentWriteAtIsRaceFree writes every chunk from its own goroutine (as downloadFile's
+// worker pool does) so that `go test -race` catches any unsynchronized access to done/nextCallbackAt/EWMA state.
+func TestTaskLocalFileConcurrentWriteAtIsRaceFree(t *testing.T) {
+	size := int64(downloadChunkSize * 8)
+	content := make([]byte, size)
+	for i := range content {
+		content[i] = byte(i % 199)
+	}
+
+	var callbacks int64
+	localFile := newTestLocalFileWithCallback(t, size, func(done, total int64, speed float64) {
+		atomic.AddInt64(&callbacks, 1)
+	})
+
+	var wg sync.WaitGroup
+	for _, j := range chunkJobs(size, downloadChunkSize) {
+		wg.Add(1)
+		go func(j chunkJob) {
+			defer wg.Done()
+			if _, err := localFile.WriteAt(content[j.offset:j.offset+j.limit], j.offset); err != nil {
+				t.Errorf("WriteAt failed: %s", err)
+			}
+		}(j)
+	}
+	wg.Wait()
+
+	if got := readAll(t, localFile); !bytes.Equal(got, content) {
+		t.Fatal("concurrent WriteAt calls produced corrupted content")
+	}
+	if localFile.done != size {
+		t.Fatalf("expected done to equal size %d after all writes, got %d", size, localFile.done)
+	}
+}
+
+func TestDownloadFileSequentialFallbackForFewThreads(t *testing.T) {
+	size := int64(downloadChunkSize*2 + 7)
+	content := make([]byte, size)
+	for i := range content {
+		content[i] = byte(i % 37)
+	}
+
+	stub := &stubFileGetter{content: content}
+	localFile := newTestLocalFile(t, size)
+
+	if err := downloadFile(context.Background(), stub, nil, size, localFile, 1); err != nil {
+		t.Fatalf("downloadFile failed: %s", err)
+	}
+
+	if got := readAll(t, localFile); !bytes.Equal(got, content) {
+		t.Fatal("downloaded content does not match source")
+	}
+}
+
+// fileGetterFunc adapts a plain function to the fileGetter interface for tests that only need to stub error paths.
+type fileGetterFunc func(ctx context.Context, req *tg.UploadGetFileRequest) (tg.UploadFileClass, error)
+
+func (f fileGetterFunc) UploadGetFile(ctx context.Context, req *tg.UploadGetFileRequest) (tg.UploadFileClass, error) {
+	return f(ctx, req)
+}
+
+func TestDownloadFilePropagatesErrorAfterExhaustingRetries(t *testing.T) {
+	size := int64(downloadChunkSize * 2)
+	localFile := newTestLocalFile(t, size)
+
+	alwaysFail := fileGetterFunc(func(ctx context.Context, req *tg.UploadGetFileRequest) (tg.UploadFileClass, error) {
+		return nil, errors.New("permanent failure")
+	})
+
+	if err := downloadFile(context.Background(), alwaysFail, nil, size, localFile, 2); err == nil {
+		t.Fatal("expected error once retries are exhausted")
+	}
+}
+
+func BenchmarkDownloadFileSequential(b *testing.B) {
+	benchmarkDownload(b, 1)
+}
+
+func BenchmarkDownloadFileChunked(b *testing.B) {
+	benchmarkDownload(b, 8)
+}
+
+func benchmarkDownload(b *testing.B, threads int) {
+	size := int64(downloadChunkSize * 8)
+	content := make([]byte, size)
+
+	for i := 0; i < b.N; i++ {
+		stub := &stubFileGetter{content: content, latency: 2 * time.Millisecond}
+		path := filepath.Join(b.TempDir(), fmt.Sprintf("bench-%d.bin", i))
+		localFile, err := NewTaskLocalFile(path, size, nil)
+		if err != nil {
+			b.Fatalf("failed to create local file: %s", err)
+		}
+		if err := downloadFile(context.Background(), stub, nil, size, localFile, threads); err != nil {
+			b.Fatalf("downloadFile failed: %s", err)
+		}
+		localFile.Close()
+	}
+}
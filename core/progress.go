@@ -0,0 +1,103 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ewmaSpeed 对瞬时速度做指数加权移动平均, 避免平均速度在慢速/高速链路上抖动;
+// 并发分块下载会从多个 goroutine 同时调用 update, 因此用 mu 保护 value/initialized
+type ewmaSpeed struct {
+	alpha float64
+
+	mu          sync.Mutex
+	value       float64
+	initialized bool
+}
+
+func newEWMASpeed(alpha float64) *ewmaSpeed {
+	return &ewmaSpeed{alpha: alpha}
+}
+
+// update 用最新一次采样到的瞬时速度(字节/秒)更新平均值
+func (e *ewmaSpeed) update(instant float64) float64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.initialized {
+		e.value = instant
+		e.initialized = true
+		return e.value
+	}
+	e.value = e.alpha*instant + (1-e.alpha)*e.value
+	return e.value
+}
+
+// eta 按当前 EWMA 速度估算剩余字节传输完成所需时间, 速度为 0 或负数时无法估算
+func eta(remaining int64, bytesPerSec float64) time.Duration {
+	if bytesPerSec <= 0 {
+		return 0
+	}
+	return time.Duration(float64(remaining)/bytesPerSec) * time.Second
+}
+
+// progressBar 用 barWidth 个字符渲染一个文本进度条
+func progressBar(progress float64, barWidth int) string {
+	if barWidth <= 0 {
+		return ""
+	}
+	filled := int(progress / 100 * float64(barWidth))
+	if filled > barWidth {
+		filled = barWidth
+	}
+	if filled < 0 {
+		filled = 0
+	}
+	return strings.Repeat("█", filled) + strings.Repeat("░", barWidth-filled)
+}
+
+// editRateLimiter 用令牌桶限制 Telegram 编辑消息的频率: 两次编辑间至少间隔 minInterval,
+// 且进度相比上次编辑至少推进 minPercentDelta, done 为 true 时(100%/任务结束)总是放行
+type editRateLimiter struct {
+	minInterval     time.Duration
+	minPercentDelta float64
+
+	lastEditAt  time.Time
+	lastPercent float64
+	everyEdited bool
+}
+
+func newEditRateLimiter(minInterval time.Duration, minPercentDelta float64) *editRateLimiter {
+	return &editRateLimiter{minInterval: minInterval, minPercentDelta: minPercentDelta}
+}
+
+// allow 判断这一次进度更新是否应该触发一次 Telegram 编辑
+func (r *editRateLimiter) allow(now time.Time, percent float64, done bool) bool {
+	if done || !r.everyEdited {
+		r.lastEditAt = now
+		r.lastPercent = percent
+		r.everyEdited = true
+		return true
+	}
+	if now.Sub(r.lastEditAt) < r.minInterval {
+		return false
+	}
+	if percent-r.lastPercent < r.minPercentDelta {
+		return false
+	}
+	r.lastEditAt = now
+	r.lastPercent = percent
+	return true
+}
+
+func formatSpeed(bytesPerSec float64) string {
+	return fmt.Sprintf("%.2fMB/s", bytesPerSec/1024/1024)
+}
+
+func formatETA(d time.Duration) string {
+	if d <= 0 {
+		return "--"
+	}
+	return d.Round(time.Second).String()
+}
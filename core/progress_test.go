@@ -0,0 +1,73 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEWMASpeedSmoothsTowardsInstant(t *testing.T) {
+	e := newEWMASpeed(0.3)
+	if got := e.update(100); got != 100 {
+		t.Fatalf("first sample should seed the average, got %f", got)
+	}
+	got := e.update(200)
+	want := 0.3*200 + 0.7*100
+	if got != want {
+		t.Fatalf("expected %f, got %f", want, got)
+	}
+}
+
+func TestETAZeroSpeedIsUnknown(t *testing.T) {
+	if d := eta(1000, 0); d != 0 {
+		t.Fatalf("expected 0 duration for zero speed, got %s", d)
+	}
+}
+
+func TestETAComputesRemainingTime(t *testing.T) {
+	d := eta(1000, 100)
+	if d != 10*time.Second {
+		t.Fatalf("expected 10s, got %s", d)
+	}
+}
+
+func TestProgressBarWidth(t *testing.T) {
+	if bar := progressBar(50, 10); bar != "█████░░░░░" {
+		t.Fatalf("unexpected bar: %q", bar)
+	}
+	if bar := progressBar(100, 10); bar != "██████████" {
+		t.Fatalf("unexpected full bar: %q", bar)
+	}
+}
+
+func TestEditRateLimiterFirstCallAlwaysAllowed(t *testing.T) {
+	r := newEditRateLimiter(3*time.Second, 1)
+	now := time.Now()
+	if !r.allow(now, 0, false) {
+		t.Fatal("expected first call to be allowed")
+	}
+}
+
+func TestEditRateLimiterThrottlesByIntervalAndPercent(t *testing.T) {
+	r := newEditRateLimiter(3*time.Second, 1)
+	now := time.Now()
+	r.allow(now, 10, false)
+
+	if r.allow(now.Add(1*time.Second), 11, false) {
+		t.Fatal("expected edit to be throttled by min interval")
+	}
+	if r.allow(now.Add(4*time.Second), 10.5, false) {
+		t.Fatal("expected edit to be throttled by min percent delta")
+	}
+	if !r.allow(now.Add(4*time.Second), 12, false) {
+		t.Fatal("expected edit once both interval and percent delta are satisfied")
+	}
+}
+
+func TestEditRateLimiterAlwaysAllowsDone(t *testing.T) {
+	r := newEditRateLimiter(3*time.Second, 1)
+	now := time.Now()
+	r.allow(now, 50, false)
+	if !r.allow(now.Add(time.Millisecond), 50.01, true) {
+		t.Fatal("expected the final/done edit to always be allowed")
+	}
+}
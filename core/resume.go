@@ -0,0 +1,120 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/celestix/gotgproto/ext"
+	"github.com/gotd/td/tg"
+	"github.com/krau/SaveAny-Bot/config"
+	"github.com/krau/SaveAny-Bot/db"
+	"github.com/krau/SaveAny-Bot/logger"
+	"github.com/krau/SaveAny-Bot/storage"
+	"github.com/krau/SaveAny-Bot/types"
+)
+
+const defaultUploadChunkSize = 8 * 1024 * 1024
+
+// saveFileResumable 通过 storage.ResumableStorage 分块上传本地文件, 重试时从上次持久化的 offset 续传,
+// 而不是像 saveFileWithRetry 那样整个文件重新上传
+func saveFileResumable(task *types.Task, taskStorage storage.ResumableStorage, localFilePath string) error {
+	retry := config.GetRetry()
+	var lastErr error
+	for i := 0; i <= retry; i++ {
+		if lastErr = uploadResumable(task, taskStorage, localFilePath); lastErr == nil {
+			if err := db.DeleteUploadState(task.Key()); err != nil {
+				logger.L.Errorf("Failed to clean up upload state: %s", err)
+			}
+			notifyTaskResult(task, nil)
+			return nil
+		}
+		logger.L.Errorf("Failed to save file: %s, retrying from last committed offset...", lastErr)
+	}
+	err := fmt.Errorf("failed to save file: %w", lastErr)
+	notifyTaskResult(task, err)
+	return err
+}
+
+func uploadResumable(task *types.Task, taskStorage storage.ResumableStorage, localFilePath string) error {
+	localFile, err := OpenTaskLocalFile(localFilePath)
+	if err != nil {
+		return err
+	}
+	defer localFile.Close()
+
+	state, err := db.GetUploadState(task.Key())
+	if err != nil || state == nil {
+		uploadID, createErr := taskStorage.CreateUpload(task.Ctx, task.StoragePath, localFile.size)
+		if createErr != nil {
+			return fmt.Errorf("failed to create upload: %w", createErr)
+		}
+		state = &storage.UploadState{
+			TaskKey:       task.Key(),
+			StorageName:   task.StorageName,
+			ChatID:        task.ReplyChatID,
+			UploadID:      uploadID,
+			ChunkSize:     defaultUploadChunkSize,
+			LocalFilePath: localFilePath,
+			StoragePath:   task.StoragePath,
+		}
+	} else if offset, offsetErr := taskStorage.GetOffset(task.Ctx, state.UploadID); offsetErr == nil {
+		state.Offset = offset
+	}
+
+	uploadErr := storage.UploadChunks(task.Ctx, taskStorage, state.UploadID, localFile, localFile.size, state.Offset, state.ChunkSize, func(offset int64) {
+		state.Offset = offset
+		if err := db.SaveUploadState(state); err != nil {
+			logger.L.Errorf("Failed to persist upload state: %s", err)
+		}
+	})
+	if uploadErr != nil {
+		return uploadErr
+	}
+
+	checksum, err := fileChecksum(localFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to checksum local file: %w", err)
+	}
+	return taskStorage.FinishUpload(task.Ctx, state.UploadID, checksum)
+}
+
+func fileChecksum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ResumeIncompleteTasks 在进程重启后读取数据库中未完成的上传状态, 并通过 Telegram 回调按钮询问用户是否续传
+func ResumeIncompleteTasks(ctx *ext.Context) error {
+	states, err := db.ListUploadStates()
+	if err != nil {
+		return fmt.Errorf("failed to list incomplete uploads: %w", err)
+	}
+	for _, state := range states {
+		text := fmt.Sprintf("检测到未完成的上传任务\n存储: %s\n已上传: %d 字节", state.StorageName, state.Offset)
+		markup := &tg.ReplyInlineMarkup{
+			Rows: []tg.KeyboardButtonRow{{Buttons: []tg.KeyboardButtonClass{
+				&tg.KeyboardButtonCallback{Text: "继续上传", Data: fmt.Appendf(nil, "resume %s", state.TaskKey)},
+				&tg.KeyboardButtonCallback{Text: "放弃", Data: fmt.Appendf(nil, "discard_resume %s", state.TaskKey)},
+			}}},
+		}
+		if _, err := ctx.SendMessage(state.ChatID, &tg.MessagesSendMessageRequest{
+			Message:     text,
+			ReplyMarkup: markup,
+		}); err != nil {
+			logger.L.Errorf("Failed to notify user about resumable upload %s: %s", state.TaskKey, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/krau/SaveAny-Bot/config"
+	"github.com/krau/SaveAny-Bot/logger"
+	"github.com/krau/SaveAny-Bot/notify"
+	"github.com/krau/SaveAny-Bot/storage"
+	"github.com/krau/SaveAny-Bot/storage/router"
+	"github.com/krau/SaveAny-Bot/types"
+)
+
+// init 把存储和通知注册表的热重载对账挂到 config 的重载回调上, 这样新增/删除/修改 storages、notify
+// 配置项在 config.toml 被编辑后即可生效, 不需要重启进程; config 包本身不能直接依赖 storage/notify,
+// 所以由同时依赖三者的 core 包来完成这个接线
+func init() {
+	config.OnReload(func(oldCfg, newCfg *config.Config) {
+		storage.Reconcile(oldCfg.Storages, newCfg.Storages)
+		notify.Reconcile(oldCfg.Notify, newCfg.Notify)
+	})
+}
+
+// InitNotify 把 config.Init() 解析出的通知渠道注册进 notify 的全局注册表, 应在 config.Init() 成功返回后
+// 调用一次; 不能放进 config.Init() 本身, 因为 notify 包依赖 config 包, 反向调用会造成 import cycle
+func InitNotify() {
+	notify.InitFromConfig(config.Cfg.Notify)
+}
+
+// resolveTaskStorage 在用户开启默认路由模式时, 通过 match 规则选出任务应使用的存储, 未命中规则时回退到 fallbackName
+func resolveTaskStorage(task *types.Task, mimeType string, fallbackName string) (storage.Storage, error) {
+	name, matched := router.New(config.GetStorages()).Resolve(task, mimeType, fallbackName)
+	if name == "" {
+		return nil, fmt.Errorf("未找到匹配的存储, 且未配置兜底存储")
+	}
+	if matched {
+		logger.L.Debugf("任务 %s 命中路由规则, 匹配到存储: %s", task.String(), name)
+	}
+	return storage.Get(name)
+}
+
+// DryRunRoute 在不下载文件的情况下预览一组样例参数会匹配到哪个存储, 供管理员命令使用
+func DryRunRoute(filename string, size int64, mimeType string, chatID int64) (string, bool) {
+	return router.New(config.GetStorages()).DryRun(filename, size, mimeType, chatID)
+}
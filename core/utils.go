@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"sync"
 	"time"
 
 	"github.com/celestix/gotgproto/ext"
@@ -11,70 +12,95 @@ import (
 	"github.com/gotd/td/telegram/message/entity"
 	"github.com/gotd/td/telegram/message/styling"
 	"github.com/gotd/td/tg"
-	"github.com/krau/SaveAny-Bot/bot"
 	"github.com/krau/SaveAny-Bot/common"
 	"github.com/krau/SaveAny-Bot/config"
 	"github.com/krau/SaveAny-Bot/logger"
+	"github.com/krau/SaveAny-Bot/notify"
 	"github.com/krau/SaveAny-Bot/storage"
 	"github.com/krau/SaveAny-Bot/types"
 )
 
 func saveFileWithRetry(task *types.Task, taskStorage storage.Storage, localFilePath string) error {
-	for i := 0; i <= config.Cfg.Retry; i++ {
+	if resumableStorage, ok := taskStorage.(storage.ResumableStorage); ok {
+		return saveFileResumable(task, resumableStorage, localFilePath)
+	}
+
+	retry := config.GetRetry()
+	for i := 0; i <= retry; i++ {
 		if err := taskStorage.Save(task.Ctx, localFilePath, task.StoragePath); err != nil {
-			if i == config.Cfg.Retry {
-				return fmt.Errorf("failed to save file: %w", err)
+			if i == retry {
+				err = fmt.Errorf("failed to save file: %w", err)
+				notifyTaskResult(task, err)
+				return err
 			}
 			logger.L.Errorf("Failed to save file: %s, retrying...", err)
 			continue
 		}
+		notifyTaskResult(task, nil)
 		return nil
 	}
 	return nil
 }
 
-func processPhoto(task *types.Task, taskStorage storage.Storage, cachePath string) error {
-	res, err := bot.Client.API().UploadGetFile(task.Ctx, &tg.UploadGetFileRequest{
-		Location: task.File.Location,
-		Offset:   0,
-		Limit:    1024 * 1024,
-	})
+// notifyTaskResult 把任务结束事件推送给该任务所属用户订阅的通知渠道, err 为 nil 表示成功
+func notifyTaskResult(task *types.Task, err error) {
+	names := config.UserNotifiers(task.ChatID)
+	if len(names) == 0 {
+		return
+	}
+	event := notify.NotifyEvent{
+		FileName:     task.FileName(),
+		StorageName:  task.StorageName,
+		StoragePath:  task.StoragePath,
+		Duration:     time.Since(task.StartTime),
+		AverageSpeed: getSpeed(task.File.FileSize, task.StartTime),
+		Err:          err,
+	}
 	if err != nil {
-		return fmt.Errorf("failed to get file: %w", err)
+		event.Type = notify.EventFailed
+	} else {
+		event.Type = notify.EventSucceeded
 	}
+	notify.SendAll(task.Ctx, names, event)
+}
 
-	result, ok := res.(*tg.UploadFile)
-	if !ok {
-		return fmt.Errorf("unexpected type %T", res)
+func processPhoto(ctx *ext.Context, task *types.Task, taskStorage storage.Storage, cachePath string) error {
+	localFile, err := NewTaskLocalFile(cachePath, task.File.FileSize, buildProgressCallback(ctx, task))
+	if err != nil {
+		return fmt.Errorf("failed to create local file: %w", err)
 	}
-
-	if err := os.WriteFile(cachePath, result.Bytes, os.ModePerm); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
+	if err := downloadChunked(task.Ctx, task, localFile, getTaskThreads(task.File.FileSize)); err != nil {
+		localFile.Close()
+		return fmt.Errorf("failed to download file: %w", err)
+	}
+	if err := localFile.Close(); err != nil {
+		return fmt.Errorf("failed to finalize local file: %w", err)
 	}
 
 	defer cleanCacheFile(cachePath)
 
 	logger.L.Infof("Downloaded file: %s", cachePath)
 
+	mimeType, mimeErr := fixTaskFileExt(task, cachePath)
+	if mimeErr != nil {
+		logger.L.Errorf("Failed to detect mime type for routing: %s", mimeErr)
+	}
+
+	if config.ShouldUseRouter(task.ChatID) && mimeType != "" {
+		if resolved, rerr := resolveTaskStorage(task, mimeType, taskStorage.GetName()); rerr != nil {
+			logger.L.Errorf("路由选择存储失败, 使用原存储: %s", rerr)
+		} else {
+			taskStorage = resolved
+			task.StorageName = resolved.GetName()
+		}
+	}
+
 	return saveFileWithRetry(task, taskStorage, cachePath)
 }
 
-// func getProgressBar(progress float64, updateCount int) string {
-// 	bar := ""
-// 	barSize := 100 / updateCount
-// 	for i := 0; i < updateCount; i++ {
-// 		if progress >= float64(barSize*(i+1)) {
-// 			bar += "█"
-// 		} else {
-// 			bar += "░"
-// 		}
-// 	}
-// 	return bar
-// }
-
 func cleanCacheFile(destPath string) {
-	if config.Cfg.Temp.CacheTTL > 0 {
-		common.RmFileAfter(destPath, time.Duration(config.Cfg.Temp.CacheTTL)*time.Second)
+	if cacheTTL := config.GetCacheTTL(); cacheTTL > 0 {
+		common.RmFileAfter(destPath, time.Duration(cacheTTL)*time.Second)
 	} else {
 		if err := os.Remove(destPath); err != nil {
 			logger.L.Errorf("Failed to purge file: %s", err)
@@ -82,19 +108,6 @@ func cleanCacheFile(destPath string) {
 	}
 }
 
-// 获取进度需要更新的次数
-func getProgressUpdateCount(fileSize int64) int {
-	updateCount := 5
-	if fileSize > 1024*1024*1000 {
-		updateCount = 50
-	} else if fileSize > 1024*1024*500 {
-		updateCount = 20
-	} else if fileSize > 1024*1024*200 {
-		updateCount = 10
-	}
-	return updateCount
-}
-
 func getSpeed(bytesRead int64, startTime time.Time) string {
 	if startTime.IsZero() {
 		return "0MB/s"
@@ -104,13 +117,20 @@ func getSpeed(bytesRead int64, startTime time.Time) string {
 	return fmt.Sprintf("%.2fMB/s", speed)
 }
 
-func buildProgressMessageEntity(task *types.Task, bytesRead int64, startTime time.Time, progress float64) (string, []tg.MessageEntityClass) {
+// buildProgressMessageEntity 渲染进度消息, speedBPS 为 EWMA 平滑后的瞬时速度(字节/秒), 用于计算 ETA 和展示的进度条
+func buildProgressMessageEntity(task *types.Task, bytesRead, contentLength int64, speedBPS float64, progress float64, barWidth int) (string, []tg.MessageEntityClass) {
+	speedText := formatSpeed(speedBPS)
+	etaText := formatETA(eta(contentLength-bytesRead, speedBPS))
+	bar := progressBar(progress, barWidth)
+
 	entityBuilder := entity.Builder{}
-	text := fmt.Sprintf("正在处理下载任务\n文件名: %s\n保存路径: %s\n平均速度: %s\n当前进度: %.2f%%",
+	text := fmt.Sprintf("正在处理下载任务\n文件名: %s\n保存路径: %s\n当前速度: %s\nETA: %s\n当前进度: %.2f%%\n%s",
 		task.FileName(),
 		fmt.Sprintf("[%s]:%s", task.StorageName, task.StoragePath),
-		getSpeed(bytesRead, startTime),
+		speedText,
+		etaText,
 		progress,
+		bar,
 	)
 	var entities []tg.MessageEntityClass
 	if err := styling.Perform(&entityBuilder,
@@ -118,10 +138,14 @@ func buildProgressMessageEntity(task *types.Task, bytesRead int64, startTime tim
 		styling.Code(task.FileName()),
 		styling.Plain("\n保存路径: "),
 		styling.Code(fmt.Sprintf("[%s]:%s", task.StorageName, task.StoragePath)),
-		styling.Plain("\n平均速度: "),
-		styling.Bold(getSpeed(bytesRead, task.StartTime)),
+		styling.Plain("\n当前速度: "),
+		styling.Bold(speedText),
+		styling.Plain("\nETA: "),
+		styling.Bold(etaText),
 		styling.Plain("\n当前进度: "),
 		styling.Bold(fmt.Sprintf("%.2f%%", progress)),
+		styling.Plain("\n"),
+		styling.Code(bar),
 	); err != nil {
 		logger.L.Errorf("Failed to build entities: %s", err)
 		return text, entities
@@ -129,15 +153,21 @@ func buildProgressMessageEntity(task *types.Task, bytesRead int64, startTime tim
 	return entityBuilder.Complete()
 }
 
-func buildProgressCallback(ctx *ext.Context, task *types.Task, updateCount int) func(bytesRead, contentLength int64) {
-	return func(bytesRead, contentLength int64) {
+// buildProgressCallback 返回写入回调: 按 editRateLimiter 的节流规则把平滑速度/ETA/进度条编辑进 Telegram 消息,
+// 既保证突发写入不会打满 Bot API 的编辑频率限制, 也保证 100% 完成时一定会有一次最终编辑
+func buildProgressCallback(ctx *ext.Context, task *types.Task) func(bytesRead, contentLength int64, speedBPS float64) {
+	progressCfg := config.GetProgressConfig()
+	limiter := newEditRateLimiter(time.Duration(progressCfg.MinEditIntervalMS)*time.Millisecond, progressCfg.MinPercentDelta)
+	return func(bytesRead, contentLength int64, speedBPS float64) {
 		progress := float64(bytesRead) / float64(contentLength) * 100
 		logger.L.Tracef("Downloading %s: %.2f%%", task.String(), progress)
-		progressInt := int(progress)
-		if task.File.FileSize < 1024*1024*50 || progressInt == 0 || progressInt%int(100/updateCount) != 0 {
+
+		done := bytesRead >= contentLength
+		if !limiter.allow(time.Now(), progress, done) {
 			return
 		}
-		text, entities := buildProgressMessageEntity(task, bytesRead, task.StartTime, progress)
+
+		text, entities := buildProgressMessageEntity(task, bytesRead, contentLength, speedBPS, progress, progressCfg.BarWidth)
 		ctx.EditMessage(task.ReplyChatID, &tg.MessagesEditMessageRequest{
 			Message:     text,
 			Entities:    entities,
@@ -153,24 +183,26 @@ func getCancelTaskMarkup(task *types.Task) *tg.ReplyInlineMarkup {
 	}
 }
 
-func fixTaskFileExt(task *types.Task, localFilePath string) {
+// fixTaskFileExt 探测本地文件的 MIME 类型, 在任务文件名缺少后缀时补全, 并把探测到的 MIME 类型字符串
+// 返回给调用方, 供 use_router 模式下匹配 match.mime_types 规则使用
+func fixTaskFileExt(task *types.Task, localFilePath string) (string, error) {
+	mimeType, err := mimetype.DetectFile(localFilePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to detect mime type: %w", err)
+	}
 	if path.Ext(task.FileName()) == "" {
-		mimeType, err := mimetype.DetectFile(localFilePath)
-		if err != nil {
-			logger.L.Errorf("Failed to detect mime type: %s", err)
-		} else {
-			task.File.FileName = fmt.Sprintf("%s%s", task.FileName(), mimeType.Extension())
-			task.StoragePath = fmt.Sprintf("%s%s", task.StoragePath, mimeType.Extension())
-		}
+		task.File.FileName = fmt.Sprintf("%s%s", task.FileName(), mimeType.Extension())
+		task.StoragePath = fmt.Sprintf("%s%s", task.StoragePath, mimeType.Extension())
 	}
+	return mimeType.String(), nil
 }
 
 func getTaskThreads(fileSize int64) int {
 	threads := 1
 	if fileSize > 1024*1024*100 {
-		threads = config.Cfg.Threads
+		threads = config.GetThreads()
 	} else if fileSize > 1024*1024*50 {
-		threads = config.Cfg.Threads / 2
+		threads = config.GetThreads() / 2
 	}
 	return threads
 }
@@ -178,11 +210,17 @@ func getTaskThreads(fileSize int64) int {
 type TaskLocalFile struct {
 	file             *os.File
 	size             int64
-	done             int64
-	progressCallback func(bytesRead, contentLength int64)
+	progressCallback func(bytesRead, contentLength int64, speedBPS float64)
 	callbackTimes    int64
-	nextCallbackAt   int64
 	callbackInterval int64
+	speed            *ewmaSpeed
+
+	// mu 保护以下字段: 并发分块下载会有多个 goroutine 同时调用 WriteAt
+	mu             sync.Mutex
+	done           int64
+	nextCallbackAt int64
+	lastSampleAt   time.Time
+	lastSampleDone int64
 }
 
 func (t *TaskLocalFile) Read(p []byte) (n int, err error) {
@@ -192,20 +230,71 @@ func (t *TaskLocalFile) Read(p []byte) (n int, err error) {
 func (t *TaskLocalFile) Close() error {
 	return t.file.Close()
 }
+// ReadAt 按偏移量读取已写入的数据, 供断点续传的分块上传按 chunk 读取本地文件
+func (t *TaskLocalFile) ReadAt(p []byte, off int64) (int, error) {
+	return t.file.ReadAt(p, off)
+}
+
+// WriteAt 供并发分块下载的多个 goroutine 在各自不重叠的 offset 上调用; os.File.WriteAt 本身对不同 offset
+// 并发安全, 但 done/nextCallbackAt/EWMA 采样这些共享簿记状态都在 mu 保护下更新, 保证回调不会因为竞态而丢失或重复触发
 func (t *TaskLocalFile) WriteAt(p []byte, off int64) (int, error) {
 	n, err := t.file.WriteAt(p, off)
 	if err != nil {
 		return n, err
 	}
+
+	t.mu.Lock()
 	t.done += int64(n)
-	if t.progressCallback != nil && t.done >= t.nextCallbackAt {
-		t.progressCallback(t.done, t.size)
+	fire := t.progressCallback != nil && (t.done >= t.nextCallbackAt || t.done >= t.size)
+	var done int64
+	var speed float64
+	if fire {
 		t.nextCallbackAt += t.callbackInterval
+		done = t.done
+		speed = t.sampleSpeedLocked()
+	}
+	t.mu.Unlock()
+
+	if fire {
+		t.progressCallback(done, t.size, speed)
 	}
 	return n, nil
 }
 
-func NewTaskLocalFile(filePath string, fileSize int64, progressCallback func(bytesRead, contentLength int64)) (*TaskLocalFile, error) {
+// sampleSpeedLocked 用自上次采样以来新写入的字节数和经过的时间算出瞬时速度, 并喂给 EWMA 得到平滑后的速度;
+// 调用方必须持有 t.mu
+func (t *TaskLocalFile) sampleSpeedLocked() float64 {
+	now := time.Now()
+	if t.lastSampleAt.IsZero() {
+		t.lastSampleAt = now
+		t.lastSampleDone = t.done
+		return t.speed.update(0)
+	}
+	elapsed := now.Sub(t.lastSampleAt).Seconds()
+	instant := 0.0
+	if elapsed > 0 {
+		instant = float64(t.done-t.lastSampleDone) / elapsed
+	}
+	t.lastSampleAt = now
+	t.lastSampleDone = t.done
+	return t.speed.update(instant)
+}
+
+// OpenTaskLocalFile 打开一个已存在的本地文件用于读取, 供断点续传的分块上传按 chunk 读取
+func OpenTaskLocalFile(filePath string) (*TaskLocalFile, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+	return &TaskLocalFile{file: file, size: info.Size()}, nil
+}
+
+func NewTaskLocalFile(filePath string, fileSize int64, progressCallback func(bytesRead, contentLength int64, speedBPS float64)) (*TaskLocalFile, error) {
 	file, err := os.Create(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open file: %w", err)
@@ -222,5 +311,6 @@ func NewTaskLocalFile(filePath string, fileSize int64, progressCallback func(byt
 		callbackTimes:    100,
 		nextCallbackAt:   callbackInterval,
 		callbackInterval: callbackInterval,
+		speed:            newEWMASpeed(config.GetProgressConfig().EWMAAlpha),
 	}, nil
 }
@@ -0,0 +1,96 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const dingtalkEndpoint = "https://oapi.dingtalk.com/robot/send"
+
+// DingtalkNotifier 通过钉钉自定义机器人 webhook 推送通知, secret 非空时按加签规则附带 timestamp/sign
+type DingtalkNotifier struct {
+	name        string
+	accessToken string
+	secret      string
+
+	now func() int64
+}
+
+// NewDingtalkNotifier 从配置的 access_token/secret 构造一个钉钉渠道
+func NewDingtalkNotifier(name, accessToken, secret string) *DingtalkNotifier {
+	return &DingtalkNotifier{name: name, accessToken: accessToken, secret: secret, now: currentTimestampMillis}
+}
+
+func (d *DingtalkNotifier) Name() string {
+	return d.name
+}
+
+func (d *DingtalkNotifier) Send(ctx context.Context, event NotifyEvent) error {
+	payload, err := json.Marshal(map[string]any{
+		"msgtype": "text",
+		"text": map[string]string{
+			"content": fmt.Sprintf("%s\n%s", eventTitle(event), eventContent(event)),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal dingtalk payload: %w", err)
+	}
+
+	reqURL, err := d.requestURL()
+	if err != nil {
+		return fmt.Errorf("failed to build dingtalk url: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, reqURL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to build dingtalk request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call dingtalk: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("dingtalk returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// requestURL 拼出带 access_token 的机器人地址, 配置了 secret 时附加 timestamp 和 HMAC-SHA256 签名
+func (d *DingtalkNotifier) requestURL() (string, error) {
+	q := url.Values{}
+	q.Set("access_token", d.accessToken)
+
+	if d.secret == "" {
+		return fmt.Sprintf("%s?%s", dingtalkEndpoint, q.Encode()), nil
+	}
+
+	timestamp := d.now()
+	sign, err := dingtalkSign(timestamp, d.secret)
+	if err != nil {
+		return "", err
+	}
+	q.Set("timestamp", strconv.FormatInt(timestamp, 10))
+	q.Set("sign", sign)
+	return fmt.Sprintf("%s?%s", dingtalkEndpoint, q.Encode()), nil
+}
+
+func dingtalkSign(timestamp int64, secret string) (string, error) {
+	stringToSign := fmt.Sprintf("%d\n%s", timestamp, secret)
+	mac := hmac.New(sha256.New, []byte(secret))
+	if _, err := mac.Write([]byte(stringToSign)); err != nil {
+		return "", fmt.Errorf("failed to compute dingtalk sign: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil)), nil
+}
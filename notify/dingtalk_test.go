@@ -0,0 +1,55 @@
+package notify
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/krau/SaveAny-Bot/config"
+)
+
+func TestDingtalkRequestURLWithoutSecret(t *testing.T) {
+	d := NewDingtalkNotifier("dt", "tok", "")
+	reqURL, err := d.requestURL()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !strings.Contains(reqURL, "access_token=tok") {
+		t.Fatalf("expected access_token in url, got %s", reqURL)
+	}
+	if strings.Contains(reqURL, "sign=") {
+		t.Fatalf("did not expect a sign param without a secret, got %s", reqURL)
+	}
+}
+
+func TestDingtalkRequestURLWithSecretIsSigned(t *testing.T) {
+	d := NewDingtalkNotifier("dt", "tok", "shh")
+	d.now = func() int64 { return 1700000000000 }
+
+	reqURL, err := d.requestURL()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	parsed, err := url.Parse(reqURL)
+	if err != nil {
+		t.Fatalf("failed to parse url: %s", err)
+	}
+	q := parsed.Query()
+	if q.Get("timestamp") != "1700000000000" {
+		t.Fatalf("expected fixed timestamp, got %s", q.Get("timestamp"))
+	}
+	wantSign, err := dingtalkSign(1700000000000, "shh")
+	if err != nil {
+		t.Fatalf("unexpected error computing sign: %s", err)
+	}
+	if q.Get("sign") != wantSign {
+		t.Fatalf("expected sign %s, got %s", wantSign, q.Get("sign"))
+	}
+}
+
+func TestNewUnsupportedType(t *testing.T) {
+	if _, err := New(config.NotifyConfig{Name: "x", Type: "unknown"}); err == nil {
+		t.Fatal("expected error for unsupported notifier type")
+	}
+}
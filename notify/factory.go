@@ -0,0 +1,99 @@
+package notify
+
+import (
+	"fmt"
+
+	"github.com/krau/SaveAny-Bot/config"
+	"github.com/krau/SaveAny-Bot/logger"
+)
+
+const (
+	TypePushPlus = "pushplus"
+	TypeDingtalk = "dingtalk"
+	TypeWebhook  = "webhook"
+)
+
+// New 根据配置里的 type 构造对应的 Notifier, Options 中缺失的字段按空字符串处理
+func New(cfg config.NotifyConfig) (Notifier, error) {
+	switch cfg.Type {
+	case TypePushPlus:
+		return NewPushPlusNotifier(cfg.Name, optionString(cfg.Options, "token"), optionString(cfg.Options, "topic")), nil
+	case TypeDingtalk:
+		return NewDingtalkNotifier(cfg.Name, optionString(cfg.Options, "access_token"), optionString(cfg.Options, "secret")), nil
+	case TypeWebhook:
+		return NewWebhookNotifier(cfg.Name, optionString(cfg.Options, "url")), nil
+	default:
+		return nil, fmt.Errorf("不支持的通知渠道类型: %s", cfg.Type)
+	}
+}
+
+// InitFromConfig 构造并注册配置中所有启用的通知渠道, 单个渠道构造失败不影响其他渠道
+func InitFromConfig(configs []config.NotifyConfig) {
+	for _, cfg := range configs {
+		if !cfg.Enable {
+			continue
+		}
+		n, err := New(cfg)
+		if err != nil {
+			logger.L.Errorf("Failed to init notifier %s: %s", cfg.Name, err)
+			continue
+		}
+		Register(n)
+	}
+}
+
+// Reconcile 按名称比较 oldConfigs/newConfigs, 只对新增或发生变化的条目重新构造并注册,
+// 对被删除或被禁用的条目从注册表摘除, 未变化的条目保留原有实例不受影响
+func Reconcile(oldConfigs, newConfigs []config.NotifyConfig) {
+	oldByName := make(map[string]config.NotifyConfig, len(oldConfigs))
+	for _, c := range oldConfigs {
+		oldByName[c.Name] = c
+	}
+	newByName := make(map[string]config.NotifyConfig, len(newConfigs))
+	for _, c := range newConfigs {
+		newByName[c.Name] = c
+	}
+
+	for name := range oldByName {
+		if _, ok := newByName[name]; !ok {
+			Unregister(name)
+		}
+	}
+
+	for name, newCfg := range newByName {
+		if oldCfg, ok := oldByName[name]; ok && sameNotifyConfig(oldCfg, newCfg) {
+			continue
+		}
+		if !newCfg.Enable {
+			Unregister(name)
+			continue
+		}
+		n, err := New(newCfg)
+		if err != nil {
+			logger.L.Errorf("重建通知渠道 %s 失败, 保留旧实例: %s", name, err)
+			continue
+		}
+		Register(n)
+	}
+}
+
+func sameNotifyConfig(a, b config.NotifyConfig) bool {
+	if a.Type != b.Type || a.Enable != b.Enable || len(a.Options) != len(b.Options) {
+		return false
+	}
+	for k, v := range a.Options {
+		if b.Options[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func optionString(options map[string]any, key string) string {
+	v, ok := options[key]
+	if !ok {
+		return ""
+	}
+	s, _ := v.(string)
+	return s
+}
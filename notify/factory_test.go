@@ -0,0 +1,76 @@
+package notify
+
+import (
+	"testing"
+
+	"github.com/krau/SaveAny-Bot/config"
+)
+
+func webhookConfig(name, url string, enable bool) config.NotifyConfig {
+	return config.NotifyConfig{
+		Name:    name,
+		Type:    TypeWebhook,
+		Enable:  enable,
+		Options: map[string]any{"url": url},
+	}
+}
+
+func TestReconcileAddsNewAndRemovesDeletedNotifiers(t *testing.T) {
+	t.Cleanup(func() { Unregister("wh1"); Unregister("wh2") })
+
+	Reconcile(nil, []config.NotifyConfig{webhookConfig("wh1", "https://a.example.com", true)})
+	if _, ok := Get("wh1"); !ok {
+		t.Fatal("expected wh1 to be registered")
+	}
+
+	Reconcile(
+		[]config.NotifyConfig{webhookConfig("wh1", "https://a.example.com", true)},
+		[]config.NotifyConfig{webhookConfig("wh2", "https://b.example.com", true)},
+	)
+	if _, ok := Get("wh1"); ok {
+		t.Fatal("expected wh1 to be unregistered after being removed from config")
+	}
+	if _, ok := Get("wh2"); !ok {
+		t.Fatal("expected wh2 to be registered")
+	}
+}
+
+func TestReconcileLeavesUnchangedNotifierUntouched(t *testing.T) {
+	t.Cleanup(func() { Unregister("wh1") })
+
+	cfg := webhookConfig("wh1", "https://a.example.com", true)
+	Reconcile(nil, []config.NotifyConfig{cfg})
+	before, _ := Get("wh1")
+
+	Reconcile([]config.NotifyConfig{cfg}, []config.NotifyConfig{cfg})
+	after, _ := Get("wh1")
+	if before != after {
+		t.Fatal("expected unchanged notify config to keep the same instance instead of rebuilding it")
+	}
+}
+
+func TestReconcileUnregistersDisabledNotifier(t *testing.T) {
+	t.Cleanup(func() { Unregister("wh1") })
+
+	cfg := webhookConfig("wh1", "https://a.example.com", true)
+	Reconcile(nil, []config.NotifyConfig{cfg})
+	if _, ok := Get("wh1"); !ok {
+		t.Fatal("expected wh1 to be registered")
+	}
+
+	disabled := cfg
+	disabled.Enable = false
+	Reconcile([]config.NotifyConfig{cfg}, []config.NotifyConfig{disabled})
+	if _, ok := Get("wh1"); ok {
+		t.Fatal("expected wh1 to be unregistered once disabled")
+	}
+}
+
+func TestInitFromConfigSkipsDisabledNotifiers(t *testing.T) {
+	t.Cleanup(func() { Unregister("wh1") })
+
+	InitFromConfig([]config.NotifyConfig{webhookConfig("wh1", "https://a.example.com", false)})
+	if _, ok := Get("wh1"); ok {
+		t.Fatal("expected disabled notifier to not be registered")
+	}
+}
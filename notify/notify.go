@@ -0,0 +1,47 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// EventType 区分一次任务通知是成功还是失败
+type EventType string
+
+const (
+	EventSucceeded EventType = "succeeded"
+	EventFailed    EventType = "failed"
+)
+
+// NotifyEvent 携带任务结束时需要推送的信息
+type NotifyEvent struct {
+	Type         EventType     `json:"type"`
+	FileName     string        `json:"file_name"`
+	StorageName  string        `json:"storage_name"`
+	StoragePath  string        `json:"storage_path"`
+	Duration     time.Duration `json:"duration"`
+	AverageSpeed string        `json:"average_speed,omitempty"`
+	Err          error         `json:"-"`
+}
+
+// Notifier 是所有推送渠道需要实现的接口
+type Notifier interface {
+	Name() string
+	Send(ctx context.Context, event NotifyEvent) error
+}
+
+func eventTitle(event NotifyEvent) string {
+	if event.Type == EventFailed {
+		return "SaveAny 任务失败"
+	}
+	return "SaveAny 任务完成"
+}
+
+func eventContent(event NotifyEvent) string {
+	if event.Type == EventFailed {
+		return fmt.Sprintf("文件: %s\n存储: %s\n错误: %s", event.FileName, event.StorageName, event.Err)
+	}
+	return fmt.Sprintf("文件: %s\n存储: %s\n保存路径: %s\n耗时: %s\n平均速度: %s",
+		event.FileName, event.StorageName, event.StoragePath, event.Duration.Round(time.Second), event.AverageSpeed)
+}
@@ -0,0 +1,56 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+const pushplusEndpoint = "https://www.pushplus.plus/send"
+
+// PushPlusNotifier 通过 PushPlus (https://www.pushplus.plus) 推送任务完成/失败通知
+type PushPlusNotifier struct {
+	name  string
+	token string
+	topic string
+}
+
+// NewPushPlusNotifier 从配置的 token/topic 构造一个 PushPlus 渠道, topic 为空时推送到发送者自己
+func NewPushPlusNotifier(name, token, topic string) *PushPlusNotifier {
+	return &PushPlusNotifier{name: name, token: token, topic: topic}
+}
+
+func (p *PushPlusNotifier) Name() string {
+	return p.name
+}
+
+func (p *PushPlusNotifier) Send(ctx context.Context, event NotifyEvent) error {
+	payload, err := json.Marshal(map[string]string{
+		"token":   p.token,
+		"title":   eventTitle(event),
+		"content": eventContent(event),
+		"topic":   p.topic,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal pushplus payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, pushplusEndpoint, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to build pushplus request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call pushplus: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("pushplus returned status %d", resp.StatusCode)
+	}
+	return nil
+}
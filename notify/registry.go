@@ -0,0 +1,49 @@
+package notify
+
+import (
+	"context"
+	"sync"
+
+	"github.com/krau/SaveAny-Bot/logger"
+)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Notifier{}
+)
+
+// Register 把一个已初始化的 Notifier 加入全局注册表, 供 SendAll 按名称查找
+func Register(n Notifier) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[n.Name()] = n
+}
+
+// Get 按名称查找一个已注册的 Notifier
+func Get(name string) (Notifier, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	n, ok := registry[name]
+	return n, ok
+}
+
+// Unregister 把一个通知渠道从全局注册表中移除, 用于配置热重载时渠道被删除或禁用的情况
+func Unregister(name string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	delete(registry, name)
+}
+
+// SendAll 把事件发送给 names 中列出的每一个 Notifier, 单个渠道失败不影响其他渠道
+func SendAll(ctx context.Context, names []string, event NotifyEvent) {
+	for _, name := range names {
+		n, ok := Get(name)
+		if !ok {
+			logger.L.Errorf("通知渠道不存在: %s", name)
+			continue
+		}
+		if err := n.Send(ctx, event); err != nil {
+			logger.L.Errorf("Failed to send notification via %s: %s", name, err)
+		}
+	}
+}
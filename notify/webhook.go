@@ -0,0 +1,73 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+func currentTimestampMillis() int64 {
+	return time.Now().UnixMilli()
+}
+
+// WebhookNotifier 把 NotifyEvent 原样编码成 JSON POST 给任意用户自定义的地址
+type WebhookNotifier struct {
+	name string
+	url  string
+}
+
+// NewWebhookNotifier 从配置的 url 构造一个通用 webhook 渠道
+func NewWebhookNotifier(name, url string) *WebhookNotifier {
+	return &WebhookNotifier{name: name, url: url}
+}
+
+func (w *WebhookNotifier) Name() string {
+	return w.name
+}
+
+func (w *WebhookNotifier) Send(ctx context.Context, event NotifyEvent) error {
+	body := struct {
+		Type         EventType `json:"type"`
+		FileName     string    `json:"file_name"`
+		StorageName  string    `json:"storage_name"`
+		StoragePath  string    `json:"storage_path"`
+		DurationMS   int64     `json:"duration_ms"`
+		AverageSpeed string    `json:"average_speed,omitempty"`
+		Error        string    `json:"error,omitempty"`
+	}{
+		Type:         event.Type,
+		FileName:     event.FileName,
+		StorageName:  event.StorageName,
+		StoragePath:  event.StoragePath,
+		DurationMS:   event.Duration.Milliseconds(),
+		AverageSpeed: event.AverageSpeed,
+	}
+	if event.Err != nil {
+		body.Error = event.Err.Error()
+	}
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
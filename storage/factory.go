@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/krau/SaveAny-Bot/config"
+	"github.com/krau/SaveAny-Bot/logger"
+)
+
+const (
+	TypeWebdav = "webdav"
+	TypeS3     = "s3"
+)
+
+// New 根据配置里的 type 构造对应的 Storage, Options 中缺失的字段按空字符串处理
+func New(cfg config.StorageConfig) (Storage, error) {
+	switch cfg.Type {
+	case TypeWebdav:
+		return NewWebdavStorage(cfg.Name, optionString(cfg.Options, "url"), optionString(cfg.Options, "username"), optionString(cfg.Options, "password")), nil
+	case TypeS3:
+		return newS3StorageFromOptions(cfg.Name, cfg.Options)
+	default:
+		return nil, fmt.Errorf("不支持的存储类型: %s", cfg.Type)
+	}
+}
+
+// newS3StorageFromOptions 从 storages 配置项里的 bucket/region/endpoint/access_key_id/secret_access_key
+// 构造一个 *s3.Client, 支持热重载时仅凭配置完整重建存储实例(不依赖进程启动时手工组装的客户端)
+func newS3StorageFromOptions(name string, options map[string]any) (Storage, error) {
+	bucket := optionString(options, "bucket")
+	if bucket == "" {
+		return nil, fmt.Errorf("s3 存储 %s 缺少 bucket 配置", name)
+	}
+
+	accessKeyID := optionString(options, "access_key_id")
+	secretAccessKey := optionString(options, "secret_access_key")
+	endpoint := optionString(options, "endpoint")
+
+	awsCfg, err := awsconfig.LoadDefaultConfig(context.Background(),
+		awsconfig.WithRegion(optionString(options, "region")),
+		awsconfig.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(accessKeyID, secretAccessKey, "")),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load aws config for s3 存储 %s: %w", name, err)
+	}
+
+	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		if endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+		}
+		o.UsePathStyle = optionBool(options, "use_path_style")
+	})
+
+	return NewS3Storage(name, bucket, client), nil
+}
+
+// Reconcile 按名称比较 oldStorages/newStorages, 只对新增或发生变化的条目重新构造并注册,
+// 对被删除或被禁用的条目从注册表摘除, 未变化的条目保留原有实例不受影响
+func Reconcile(oldStorages, newStorages []config.StorageConfig) {
+	oldByName := make(map[string]config.StorageConfig, len(oldStorages))
+	for _, s := range oldStorages {
+		oldByName[s.Name] = s
+	}
+	newByName := make(map[string]config.StorageConfig, len(newStorages))
+	for _, s := range newStorages {
+		newByName[s.Name] = s
+	}
+
+	for name := range oldByName {
+		if _, ok := newByName[name]; !ok {
+			Unregister(name)
+		}
+	}
+
+	for name, newCfg := range newByName {
+		if oldCfg, ok := oldByName[name]; ok && sameStorageConfig(oldCfg, newCfg) {
+			continue
+		}
+		if !newCfg.Enable {
+			Unregister(name)
+			continue
+		}
+		s, err := New(newCfg)
+		if err != nil {
+			logger.L.Errorf("重建存储 %s 失败, 保留旧实例: %s", name, err)
+			continue
+		}
+		Register(name, s)
+	}
+}
+
+func sameStorageConfig(a, b config.StorageConfig) bool {
+	if a.Type != b.Type || a.Enable != b.Enable || len(a.Options) != len(b.Options) {
+		return false
+	}
+	for k, v := range a.Options {
+		if b.Options[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func optionString(options map[string]any, key string) string {
+	v, ok := options[key]
+	if !ok {
+		return ""
+	}
+	s, _ := v.(string)
+	return s
+}
+
+func optionBool(options map[string]any, key string) bool {
+	v, ok := options[key]
+	if !ok {
+		return false
+	}
+	b, _ := v.(bool)
+	return b
+}
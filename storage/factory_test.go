@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/krau/SaveAny-Bot/config"
+)
+
+func webdavConfig(name, url string, enable bool) config.StorageConfig {
+	return config.StorageConfig{
+		Name:    name,
+		Type:    TypeWebdav,
+		Enable:  enable,
+		Options: map[string]any{"url": url},
+	}
+}
+
+func TestReconcileAddsNewAndRemovesDeletedStorages(t *testing.T) {
+	t.Cleanup(func() { Unregister("wd1"); Unregister("wd2") })
+
+	Reconcile(nil, []config.StorageConfig{webdavConfig("wd1", "https://a.example.com", true)})
+	if _, err := Get("wd1"); err != nil {
+		t.Fatalf("expected wd1 to be registered: %s", err)
+	}
+
+	Reconcile(
+		[]config.StorageConfig{webdavConfig("wd1", "https://a.example.com", true)},
+		[]config.StorageConfig{webdavConfig("wd2", "https://b.example.com", true)},
+	)
+	if _, err := Get("wd1"); err == nil {
+		t.Fatal("expected wd1 to be unregistered after being removed from config")
+	}
+	if _, err := Get("wd2"); err != nil {
+		t.Fatalf("expected wd2 to be registered: %s", err)
+	}
+}
+
+func TestReconcileLeavesUnchangedStorageUntouched(t *testing.T) {
+	t.Cleanup(func() { Unregister("wd1") })
+
+	cfg := webdavConfig("wd1", "https://a.example.com", true)
+	Reconcile(nil, []config.StorageConfig{cfg})
+	before, err := Get("wd1")
+	if err != nil {
+		t.Fatalf("expected wd1 to be registered: %s", err)
+	}
+
+	Reconcile([]config.StorageConfig{cfg}, []config.StorageConfig{cfg})
+	after, err := Get("wd1")
+	if err != nil {
+		t.Fatalf("expected wd1 to still be registered: %s", err)
+	}
+	if before != after {
+		t.Fatal("expected unchanged storage config to keep the same instance instead of rebuilding it")
+	}
+}
+
+func TestNewS3StorageRequiresBucket(t *testing.T) {
+	_, err := New(config.StorageConfig{Name: "s3missing", Type: TypeS3, Enable: true, Options: map[string]any{"region": "us-east-1"}})
+	if err == nil {
+		t.Fatal("expected error when s3 storage config is missing bucket")
+	}
+}
+
+func TestReconcileRebuildsS3StorageOnOptionChange(t *testing.T) {
+	t.Cleanup(func() { Unregister("s3a") })
+
+	base := config.StorageConfig{
+		Name: "s3a", Type: TypeS3, Enable: true,
+		Options: map[string]any{"bucket": "bucket-a", "region": "us-east-1", "access_key_id": "ak", "secret_access_key": "sk"},
+	}
+	Reconcile(nil, []config.StorageConfig{base})
+	if _, err := Get("s3a"); err != nil {
+		t.Fatalf("expected s3a to be registered: %s", err)
+	}
+
+	changed := base
+	changed.Options = map[string]any{"bucket": "bucket-b", "region": "us-east-1", "access_key_id": "ak", "secret_access_key": "sk"}
+	Reconcile([]config.StorageConfig{base}, []config.StorageConfig{changed})
+	got, err := Get("s3a")
+	if err != nil {
+		t.Fatalf("expected s3a to remain registered after bucket change: %s", err)
+	}
+	if s, ok := got.(*S3Storage); !ok || s.bucket != "bucket-b" {
+		t.Fatalf("expected rebuilt s3a to use bucket-b, got %+v", got)
+	}
+}
+
+func TestReconcileUnregistersDisabledStorage(t *testing.T) {
+	t.Cleanup(func() { Unregister("wd1") })
+
+	cfg := webdavConfig("wd1", "https://a.example.com", true)
+	Reconcile(nil, []config.StorageConfig{cfg})
+	if _, err := Get("wd1"); err != nil {
+		t.Fatalf("expected wd1 to be registered: %s", err)
+	}
+
+	disabled := cfg
+	disabled.Enable = false
+	Reconcile([]config.StorageConfig{cfg}, []config.StorageConfig{disabled})
+	if _, err := Get("wd1"); err == nil {
+		t.Fatal("expected wd1 to be unregistered once disabled")
+	}
+}
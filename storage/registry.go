@@ -0,0 +1,36 @@
+package storage
+
+import (
+	"fmt"
+	"sync"
+)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Storage{}
+)
+
+// Register 把一个已初始化的存储实例加入全局注册表, 供 Get 按名称查找
+func Register(name string, s Storage) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = s
+}
+
+// Get 按名称从已注册的存储中查找一个 Storage 实例
+func Get(name string) (Storage, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	s, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("存储不存在: %s", name)
+	}
+	return s, nil
+}
+
+// Unregister 把一个存储实例从全局注册表中移除, 用于配置热重载时存储被删除或禁用的情况
+func Unregister(name string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	delete(registry, name)
+}
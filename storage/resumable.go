@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+)
+
+// ResumableStorage 是存储后端可选实现的接口, 实现后 core.saveFileWithRetry 在重试时只补传上次失败后剩余的字节,
+// 而不是重新上传整个文件, 具体语义参照 tus 断点续传协议
+type ResumableStorage interface {
+	Storage
+
+	// CreateUpload 在远端创建一个新的分块上传会话, 返回用于后续续传的上传 ID
+	CreateUpload(ctx context.Context, storagePath string, size int64) (uploadID string, err error)
+	// WriteChunk 从 offset 开始写入 n 字节, 返回写入后远端已确认的新 offset
+	WriteChunk(ctx context.Context, uploadID string, offset int64, data io.Reader, n int64) (newOffset int64, err error)
+	// FinishUpload 提交并校验上传, checksum 为本地文件的完整性校验值
+	FinishUpload(ctx context.Context, uploadID string, checksum string) error
+	// GetOffset 查询远端已确认收到的字节偏移量, 用于重试时决定从哪里续传
+	GetOffset(ctx context.Context, uploadID string) (int64, error)
+}
+
+// UploadState 描述一次分块上传的持久化状态, 与任务记录一起存放在数据库中, 以 task.Key() 为键
+type UploadState struct {
+	TaskKey       string `json:"task_key"`
+	StorageName   string `json:"storage_name"`
+	ChatID        int64  `json:"chat_id"`
+	UploadID      string `json:"upload_id"`
+	Offset        int64  `json:"offset"`
+	ChunkSize     int64  `json:"chunk_size"`
+	LocalFilePath string `json:"local_file_path"`
+	StoragePath   string `json:"storage_path"`
+}
+
+// UploadChunks 从 startOffset 开始按 chunkSize 把 r 中的数据分块写入 rs, 每写完一块都会调用 onProgress,
+// 调用方应在 onProgress 中持久化最新 offset, 以便下次重试时传入同样的 startOffset 续传
+func UploadChunks(ctx context.Context, rs ResumableStorage, uploadID string, r io.ReaderAt, size, startOffset, chunkSize int64, onProgress func(offset int64)) error {
+	if chunkSize <= 0 {
+		return fmt.Errorf("chunkSize must be positive")
+	}
+
+	buf := make([]byte, chunkSize)
+	offset := startOffset
+	for offset < size {
+		n := chunkSize
+		if remaining := size - offset; remaining < n {
+			n = remaining
+		}
+		if _, err := r.ReadAt(buf[:n], offset); err != nil && err != io.EOF {
+			return fmt.Errorf("failed to read chunk at offset %d: %w", offset, err)
+		}
+
+		newOffset, err := rs.WriteChunk(ctx, uploadID, offset, bytes.NewReader(buf[:n]), n)
+		if err != nil {
+			return fmt.Errorf("failed to write chunk at offset %d: %w", offset, err)
+		}
+		offset = newOffset
+		if onProgress != nil {
+			onProgress(offset)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+)
+
+type fakeResumableStorage struct {
+	data       []byte
+	failAfter  int64
+	failed     bool
+	writeCalls int
+}
+
+func (f *fakeResumableStorage) GetName() string { return "fake" }
+func (f *fakeResumableStorage) GetType() string { return "fake" }
+
+func (f *fakeResumableStorage) Save(ctx context.Context, localFilePath, storagePath string) error {
+	return nil
+}
+
+func (f *fakeResumableStorage) CreateUpload(ctx context.Context, storagePath string, size int64) (string, error) {
+	f.data = make([]byte, 0, size)
+	return "upload-1", nil
+}
+
+func (f *fakeResumableStorage) WriteChunk(ctx context.Context, uploadID string, offset int64, data io.Reader, n int64) (int64, error) {
+	f.writeCalls++
+	if !f.failed && f.failAfter > 0 && offset+n > f.failAfter {
+		f.failed = true
+		return offset, io.ErrUnexpectedEOF
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(data, buf); err != nil {
+		return offset, err
+	}
+	f.data = append(f.data[:offset], buf...)
+	return offset + n, nil
+}
+
+func (f *fakeResumableStorage) FinishUpload(ctx context.Context, uploadID string, checksum string) error {
+	return nil
+}
+
+func (f *fakeResumableStorage) GetOffset(ctx context.Context, uploadID string) (int64, error) {
+	return int64(len(f.data)), nil
+}
+
+func TestUploadChunksResumesAfterFailureWithoutDuplicating(t *testing.T) {
+	content := append(bytes.Repeat([]byte("a"), 10), bytes.Repeat([]byte("b"), 10)...)
+	r := bytes.NewReader(content)
+
+	fake := &fakeResumableStorage{failAfter: 12}
+	uploadID, err := fake.CreateUpload(context.Background(), "dest", int64(len(content)))
+	if err != nil {
+		t.Fatalf("CreateUpload failed: %s", err)
+	}
+
+	var lastOffset int64
+	err = UploadChunks(context.Background(), fake, uploadID, r, int64(len(content)), 0, 5, func(offset int64) {
+		lastOffset = offset
+	})
+	if err == nil {
+		t.Fatal("expected first attempt to fail mid-upload")
+	}
+
+	resumeFrom, err := fake.GetOffset(context.Background(), uploadID)
+	if err != nil {
+		t.Fatalf("GetOffset failed: %s", err)
+	}
+	if resumeFrom != lastOffset {
+		t.Fatalf("expected persisted offset %d to match last committed offset %d", resumeFrom, lastOffset)
+	}
+
+	writesBeforeResume := fake.writeCalls
+	if err := UploadChunks(context.Background(), fake, uploadID, r, int64(len(content)), resumeFrom, 5, func(offset int64) {
+		lastOffset = offset
+	}); err != nil {
+		t.Fatalf("resume attempt failed: %s", err)
+	}
+
+	if !bytes.Equal(fake.data, content) {
+		t.Fatalf("expected full content after resume, got %q", fake.data)
+	}
+	// 20 字节内容、chunk 为 5, 第一次在 offset 10 失败, 续传只应再写剩余的 2 块
+	if got := fake.writeCalls - writesBeforeResume; got != 2 {
+		t.Fatalf("expected resume to write only the 2 remaining chunks, got %d writes", got)
+	}
+}
+
+func TestUploadChunksRejectsNonPositiveChunkSize(t *testing.T) {
+	fake := &fakeResumableStorage{}
+	r := bytes.NewReader([]byte("abc"))
+	if err := UploadChunks(context.Background(), fake, "upload-1", r, 3, 0, 0, nil); err == nil {
+		t.Fatal("expected error for non-positive chunk size")
+	}
+}
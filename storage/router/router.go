@@ -0,0 +1,92 @@
+package router
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/krau/SaveAny-Bot/config"
+	"github.com/krau/SaveAny-Bot/types"
+)
+
+// Router 按存储在配置中出现的顺序做 first-match-wins 的规则匹配
+type Router struct {
+	storages []config.StorageConfig
+}
+
+func New(storages []config.StorageConfig) *Router {
+	return &Router{storages: storages}
+}
+
+// Resolve 为任务选出应使用的存储名; ok 为 false 表示回退到了兜底存储, 而非命中了某条规则
+func (r *Router) Resolve(task *types.Task, mimeType string, fallback string) (name string, ok bool) {
+	if name, ok := r.DryRun(task.FileName(), task.File.FileSize, mimeType, task.ChatID); ok {
+		return name, true
+	}
+	return fallback, false
+}
+
+// DryRun 在不依赖 *types.Task 的情况下跑一遍规则, 供管理员命令预览一组样例参数会落到哪个存储
+func (r *Router) DryRun(filename string, size int64, mimeType string, chatID int64) (name string, ok bool) {
+	for _, s := range r.storages {
+		if s.Match == nil {
+			continue
+		}
+		if matches(s.Match, filename, size, mimeType, chatID) {
+			return s.GetName(), true
+		}
+	}
+	return "", false
+}
+
+func matches(m *config.MatchConfig, filename string, size int64, mimeType string, chatID int64) bool {
+	if len(m.Extensions) > 0 && !matchExtension(m.Extensions, filename) {
+		return false
+	}
+	if len(m.MimeTypes) > 0 && !matchMimeType(m.MimeTypes, mimeType) {
+		return false
+	}
+	if m.MinSize > 0 && size < m.MinSize {
+		return false
+	}
+	if m.MaxSize > 0 && size > m.MaxSize {
+		return false
+	}
+	if len(m.ChatIDs) > 0 && !matchChatID(m.ChatIDs, chatID) {
+		return false
+	}
+	return true
+}
+
+func matchExtension(exts []string, filename string) bool {
+	ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(filename)), ".")
+	for _, e := range exts {
+		if strings.TrimPrefix(strings.ToLower(e), ".") == ext {
+			return true
+		}
+	}
+	return false
+}
+
+func matchMimeType(mimeTypes []string, mimeType string) bool {
+	for _, mt := range mimeTypes {
+		if strings.HasSuffix(mt, "/*") {
+			if strings.HasPrefix(mimeType, strings.TrimSuffix(mt, "*")) {
+				return true
+			}
+			continue
+		}
+		if mt == mimeType {
+			return true
+		}
+	}
+	return false
+}
+
+func matchChatID(chatIDs []int64, chatID int64) bool {
+	for _, id := range chatIDs {
+		if id == chatID {
+			return true
+		}
+	}
+	return false
+}
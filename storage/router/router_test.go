@@ -0,0 +1,76 @@
+package router
+
+import (
+	"testing"
+
+	"github.com/krau/SaveAny-Bot/config"
+)
+
+func newStorage(name string, match *config.MatchConfig) config.StorageConfig {
+	return config.StorageConfig{Name: name, Match: match}
+}
+
+func TestDryRunFirstMatchWins(t *testing.T) {
+	r := New([]config.StorageConfig{
+		newStorage("alist", &config.MatchConfig{MinSize: 200 * 1024 * 1024, Extensions: []string{"mp4", "mkv"}}),
+		newStorage("local", &config.MatchConfig{MimeTypes: []string{"image/*"}}),
+		newStorage("s3", &config.MatchConfig{Extensions: []string{"pdf"}}),
+	})
+
+	name, ok := r.DryRun("movie.mkv", 300*1024*1024, "video/x-matroska", 0)
+	if !ok || name != "alist" {
+		t.Fatalf("expected alist, got %s (ok=%v)", name, ok)
+	}
+
+	name, ok = r.DryRun("photo.jpg", 1024, "image/jpeg", 0)
+	if !ok || name != "local" {
+		t.Fatalf("expected local, got %s (ok=%v)", name, ok)
+	}
+
+	name, ok = r.DryRun("doc.pdf", 1024, "application/pdf", 0)
+	if !ok || name != "s3" {
+		t.Fatalf("expected s3, got %s (ok=%v)", name, ok)
+	}
+}
+
+func TestDryRunNoMatchReturnsNotOK(t *testing.T) {
+	r := New([]config.StorageConfig{
+		newStorage("s3", &config.MatchConfig{Extensions: []string{"pdf"}}),
+	})
+
+	name, ok := r.DryRun("movie.mkv", 1024, "video/x-matroska", 0)
+	if ok || name != "" {
+		t.Fatalf("expected no match, got %s (ok=%v)", name, ok)
+	}
+}
+
+func TestDryRunIgnoresStorageWithoutMatch(t *testing.T) {
+	r := New([]config.StorageConfig{
+		newStorage("default", nil),
+		newStorage("s3", &config.MatchConfig{Extensions: []string{"pdf"}}),
+	})
+
+	name, ok := r.DryRun("doc.pdf", 1024, "application/pdf", 0)
+	if !ok || name != "s3" {
+		t.Fatalf("expected s3, got %s (ok=%v)", name, ok)
+	}
+}
+
+func TestMatchChatID(t *testing.T) {
+	m := &config.MatchConfig{ChatIDs: []int64{100, 200}}
+	if !matchChatID(m.ChatIDs, 200) {
+		t.Fatal("expected chat id 200 to match")
+	}
+	if matchChatID(m.ChatIDs, 300) {
+		t.Fatal("expected chat id 300 not to match")
+	}
+}
+
+func TestMatchMimeTypeWildcard(t *testing.T) {
+	if !matchMimeType([]string{"image/*"}, "image/png") {
+		t.Fatal("expected image/png to match image/*")
+	}
+	if matchMimeType([]string{"image/*"}, "video/mp4") {
+		t.Fatal("expected video/mp4 not to match image/*")
+	}
+}
@@ -0,0 +1,190 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// S3Storage 通过 S3 Multipart Upload API 实现分块续传, uploadID 编码为 "<key>\x00<S3 UploadId>"
+type S3Storage struct {
+	name   string
+	bucket string
+	client *s3.Client
+}
+
+// NewS3Storage 用一个已配置好 endpoint/区域/凭证的 *s3.Client 构造一个 S3Storage
+func NewS3Storage(name, bucket string, client *s3.Client) *S3Storage {
+	return &S3Storage{name: name, bucket: bucket, client: client}
+}
+
+func (s *S3Storage) GetName() string { return s.name }
+func (s *S3Storage) GetType() string { return "s3" }
+
+// Save 用普通的 PutObject 整体上传本地文件
+func (s *S3Storage) Save(ctx context.Context, localFilePath, storagePath string) error {
+	f, err := os.Open(localFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to open local file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(storagePath),
+		Body:   f,
+	}); err != nil {
+		return fmt.Errorf("failed to put object: %w", err)
+	}
+	return nil
+}
+
+// CreateUpload 发起一个 S3 Multipart Upload 会话
+func (s *S3Storage) CreateUpload(ctx context.Context, storagePath string, size int64) (string, error) {
+	out, err := s.client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(storagePath),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create multipart upload: %w", err)
+	}
+	return encodeS3UploadID(storagePath, aws.ToString(out.UploadId)), nil
+}
+
+// WriteChunk 把这一块当作下一个 part 上传; part 编号由远端已有的 part 数量决定, 这样重试/续传时
+// 即便本地没有缓存 part 列表也能从 ListParts 查到的真实状态继续, 不会上传出重复或跳号的 part
+func (s *S3Storage) WriteChunk(ctx context.Context, uploadID string, offset int64, data io.Reader, n int64) (int64, error) {
+	key, awsUploadID, err := decodeS3UploadID(uploadID)
+	if err != nil {
+		return offset, err
+	}
+
+	parts, err := s.listParts(ctx, key, awsUploadID)
+	if err != nil {
+		return offset, fmt.Errorf("failed to list existing parts: %w", err)
+	}
+	partNumber := int32(len(parts)) + 1
+
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(data, buf); err != nil {
+		return offset, fmt.Errorf("failed to read chunk: %w", err)
+	}
+	if _, err := s.client.UploadPart(ctx, &s3.UploadPartInput{
+		Bucket:     aws.String(s.bucket),
+		Key:        aws.String(key),
+		UploadId:   aws.String(awsUploadID),
+		PartNumber: aws.Int32(partNumber),
+		Body:       bytes.NewReader(buf),
+	}); err != nil {
+		return offset, fmt.Errorf("failed to upload part %d: %w", partNumber, err)
+	}
+	return offset + n, nil
+}
+
+// FinishUpload 用远端记录的 part 列表(而非本地状态)拼出 CompletedParts, 提交 CompleteMultipartUpload;
+// S3 Multipart 的 ETag 是分块哈希的组合值, 不能直接当作整个文件的校验和, 所以完成后重新 GetObject
+// 回整个文件并比对 checksum, 确保拼接结果和本地文件一致, checksum 为空时跳过校验
+func (s *S3Storage) FinishUpload(ctx context.Context, uploadID string, checksum string) error {
+	key, awsUploadID, err := decodeS3UploadID(uploadID)
+	if err != nil {
+		return err
+	}
+
+	parts, err := s.listParts(ctx, key, awsUploadID)
+	if err != nil {
+		return fmt.Errorf("failed to list parts before completing upload: %w", err)
+	}
+	completed := make([]types.CompletedPart, len(parts))
+	for i, p := range parts {
+		completed[i] = types.CompletedPart{ETag: p.ETag, PartNumber: p.PartNumber}
+	}
+
+	if _, err := s.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(s.bucket),
+		Key:             aws.String(key),
+		UploadId:        aws.String(awsUploadID),
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: completed},
+	}); err != nil {
+		return fmt.Errorf("failed to complete multipart upload: %w", err)
+	}
+
+	if checksum == "" {
+		return nil
+	}
+	got, err := s.remoteChecksum(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to verify uploaded object: %w", err)
+	}
+	if got != checksum {
+		return fmt.Errorf("checksum mismatch after upload: expected %s, got %s", checksum, got)
+	}
+	return nil
+}
+
+// remoteChecksum 重新 GetObject 回整个远端对象并计算 sha256, 用于 FinishUpload 校验上传完整性
+func (s *S3Storage) remoteChecksum(ctx context.Context, key string) (string, error) {
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get uploaded object: %w", err)
+	}
+	defer out.Body.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, out.Body); err != nil {
+		return "", fmt.Errorf("failed to hash object body: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// GetOffset 累加远端已确认的 part 大小, 用作断点续传重试时的起始 offset
+func (s *S3Storage) GetOffset(ctx context.Context, uploadID string) (int64, error) {
+	key, awsUploadID, err := decodeS3UploadID(uploadID)
+	if err != nil {
+		return 0, err
+	}
+	parts, err := s.listParts(ctx, key, awsUploadID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list parts: %w", err)
+	}
+	var offset int64
+	for _, p := range parts {
+		offset += aws.ToInt64(p.Size)
+	}
+	return offset, nil
+}
+
+func (s *S3Storage) listParts(ctx context.Context, key, awsUploadID string) ([]types.Part, error) {
+	out, err := s.client.ListParts(ctx, &s3.ListPartsInput{
+		Bucket:   aws.String(s.bucket),
+		Key:      aws.String(key),
+		UploadId: aws.String(awsUploadID),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out.Parts, nil
+}
+
+func encodeS3UploadID(key, awsUploadID string) string {
+	return key + "\x00" + awsUploadID
+}
+
+func decodeS3UploadID(uploadID string) (key, awsUploadID string, err error) {
+	parts := strings.SplitN(uploadID, "\x00", 2)
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("invalid s3 upload id: %s", uploadID)
+	}
+	return parts[0], parts[1], nil
+}
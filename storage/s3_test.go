@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+func TestS3UploadIDRoundTrip(t *testing.T) {
+	id := encodeS3UploadID("path/to/file.zip", "aws-upload-id")
+
+	key, awsUploadID, err := decodeS3UploadID(id)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if key != "path/to/file.zip" {
+		t.Fatalf("expected key %q, got %q", "path/to/file.zip", key)
+	}
+	if awsUploadID != "aws-upload-id" {
+		t.Fatalf("expected upload id %q, got %q", "aws-upload-id", awsUploadID)
+	}
+}
+
+func TestDecodeS3UploadIDRejectsMalformedInput(t *testing.T) {
+	if _, _, err := decodeS3UploadID("not-a-valid-id"); err == nil {
+		t.Fatal("expected error for upload id missing the key/uploadID separator")
+	}
+}
+
+func testS3Storage(t *testing.T, serverURL string) *S3Storage {
+	t.Helper()
+	client := s3.New(s3.Options{
+		Region:       "us-east-1",
+		Credentials:  aws.AnonymousCredentials{},
+		BaseEndpoint: aws.String(serverURL),
+		UsePathStyle: true,
+	})
+	return NewS3Storage("s3", "bucket", client)
+}
+
+func TestS3RemoteChecksumMatchesObjectContent(t *testing.T) {
+	content := "hello s3"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(content))
+	}))
+	defer srv.Close()
+
+	s := testS3Storage(t, srv.URL)
+	got, err := s.remoteChecksum(context.Background(), "dest.bin")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	sum := sha256.Sum256([]byte(content))
+	if want := hex.EncodeToString(sum[:]); got != want {
+		t.Fatalf("expected checksum %s, got %s", want, got)
+	}
+}
@@ -0,0 +1,10 @@
+package storage
+
+import "context"
+
+// Storage 是所有存储后端必须实现的接口
+type Storage interface {
+	GetName() string
+	GetType() string
+	Save(ctx context.Context, localFilePath, storagePath string) error
+}
@@ -0,0 +1,168 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// WebdavStorage 通过标准 WebDAV 协议访问远端存储; 断点续传依赖大多数 WebDAV 实现支持的
+// 非标准 PUT + Content-Range 扩展(Range-PUT), uploadID 即目标路径本身, 没有额外的会话概念
+type WebdavStorage struct {
+	name     string
+	baseURL  string
+	username string
+	password string
+	client   *http.Client
+}
+
+// NewWebdavStorage 用 baseURL/username/password 构造一个 WebdavStorage, username 为空时不发送 Basic Auth
+func NewWebdavStorage(name, baseURL, username, password string) *WebdavStorage {
+	return &WebdavStorage{
+		name:     name,
+		baseURL:  strings.TrimRight(baseURL, "/"),
+		username: username,
+		password: password,
+		client:   http.DefaultClient,
+	}
+}
+
+func (w *WebdavStorage) GetName() string { return w.name }
+func (w *WebdavStorage) GetType() string { return "webdav" }
+
+// Save 把本地文件整体 PUT 到远端对应路径
+func (w *WebdavStorage) Save(ctx context.Context, localFilePath, storagePath string) error {
+	f, err := os.Open(localFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to open local file: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat local file: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, w.destURL(storagePath), f)
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	req.ContentLength = info.Size()
+	w.authenticate(req)
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to put file: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webdav PUT failed with status %s", resp.Status)
+	}
+	return nil
+}
+
+// CreateUpload WebDAV 没有显式的上传会话, 直接用目标路径作为 uploadID 供后续 WriteChunk/GetOffset 使用
+func (w *WebdavStorage) CreateUpload(ctx context.Context, storagePath string, size int64) (string, error) {
+	return storagePath, nil
+}
+
+// WriteChunk 用 Content-Range 头把 [offset, offset+n) 这一段 PUT 到远端, 依赖服务端按 Range-PUT 语义追加写入
+func (w *WebdavStorage) WriteChunk(ctx context.Context, uploadID string, offset int64, data io.Reader, n int64) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, w.destURL(uploadID), data)
+	if err != nil {
+		return offset, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.ContentLength = n
+	req.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/*", offset, offset+n-1))
+	w.authenticate(req)
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return offset, fmt.Errorf("failed to put chunk at offset %d: %w", offset, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return offset, fmt.Errorf("webdav range PUT failed with status %s", resp.Status)
+	}
+	return offset + n, nil
+}
+
+// FinishUpload 分块已经按 Range-PUT 直接写入目标文件, 不需要额外的提交步骤; 但重新 GET 回目标文件并
+// 比对 checksum, 确保最后一段传输没有被截断或损坏, checksum 为空时跳过校验
+func (w *WebdavStorage) FinishUpload(ctx context.Context, uploadID string, checksum string) error {
+	if checksum == "" {
+		return nil
+	}
+	got, err := w.remoteChecksum(ctx, uploadID)
+	if err != nil {
+		return fmt.Errorf("failed to verify uploaded file: %w", err)
+	}
+	if got != checksum {
+		return fmt.Errorf("checksum mismatch after upload: expected %s, got %s", checksum, got)
+	}
+	return nil
+}
+
+// remoteChecksum 重新 GET 整个远端文件并计算 sha256, 用于 FinishUpload 校验上传完整性
+func (w *WebdavStorage) remoteChecksum(ctx context.Context, storagePath string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, w.destURL(storagePath), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to build request: %w", err)
+	}
+	w.authenticate(req)
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to get uploaded file: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return "", fmt.Errorf("webdav GET failed with status %s", resp.Status)
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(h, resp.Body); err != nil {
+		return "", fmt.Errorf("failed to hash response body: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// GetOffset 用 HEAD 请求查询远端已接收的字节数, 文件尚不存在时视为 offset 0
+func (w *WebdavStorage) GetOffset(ctx context.Context, uploadID string) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, w.destURL(uploadID), nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to build request: %w", err)
+	}
+	w.authenticate(req)
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query upload state: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return 0, nil
+	}
+	if resp.StatusCode >= 300 {
+		return 0, fmt.Errorf("webdav HEAD failed with status %s", resp.Status)
+	}
+	if resp.ContentLength < 0 {
+		return 0, nil
+	}
+	return resp.ContentLength, nil
+}
+
+func (w *WebdavStorage) destURL(storagePath string) string {
+	return w.baseURL + "/" + strings.TrimLeft(storagePath, "/")
+}
+
+func (w *WebdavStorage) authenticate(req *http.Request) {
+	if w.username != "" {
+		req.SetBasicAuth(w.username, w.password)
+	}
+}
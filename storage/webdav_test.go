@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWebdavDestURLJoinsPathsWithoutDoubleSlash(t *testing.T) {
+	w := NewWebdavStorage("wd", "https://dav.example.com/base/", "", "")
+	if got, want := w.destURL("/dir/file.zip"), "https://dav.example.com/base/dir/file.zip"; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestWebdavWriteChunkSendsContentRangeAndAuth(t *testing.T) {
+	var gotRange, gotAuthUser string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotRange = r.Header.Get("Content-Range")
+		gotAuthUser, _, _ = r.BasicAuth()
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	wd := NewWebdavStorage("wd", srv.URL, "alice", "secret")
+	newOffset, err := wd.WriteChunk(context.Background(), "dest.bin", 10, strings.NewReader("hello"), 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if newOffset != 15 {
+		t.Fatalf("expected new offset 15, got %d", newOffset)
+	}
+	if gotRange != "bytes 10-14/*" {
+		t.Fatalf("expected Content-Range %q, got %q", "bytes 10-14/*", gotRange)
+	}
+	if gotAuthUser != "alice" {
+		t.Fatalf("expected basic auth user %q, got %q", "alice", gotAuthUser)
+	}
+}
+
+func TestWebdavGetOffsetReturnsZeroWhenNotFound(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	wd := NewWebdavStorage("wd", srv.URL, "", "")
+	offset, err := wd.GetOffset(context.Background(), "missing.bin")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if offset != 0 {
+		t.Fatalf("expected offset 0 for missing file, got %d", offset)
+	}
+}
+
+func TestWebdavFinishUploadAcceptsMatchingChecksum(t *testing.T) {
+	content := "hello world"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(content))
+	}))
+	defer srv.Close()
+
+	sum := sha256.Sum256([]byte(content))
+	wd := NewWebdavStorage("wd", srv.URL, "", "")
+	if err := wd.FinishUpload(context.Background(), "dest.bin", hex.EncodeToString(sum[:])); err != nil {
+		t.Fatalf("expected matching checksum to pass, got error: %s", err)
+	}
+}
+
+func TestWebdavFinishUploadRejectsMismatchedChecksum(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("corrupted content"))
+	}))
+	defer srv.Close()
+
+	wd := NewWebdavStorage("wd", srv.URL, "", "")
+	if err := wd.FinishUpload(context.Background(), "dest.bin", "deadbeef"); err == nil {
+		t.Fatal("expected checksum mismatch to be reported as an error")
+	}
+}